@@ -0,0 +1,315 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Severity ranks how much a finding weakens the isolation of a container.
+type Severity int
+
+const (
+	SeverityNone Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "none"
+	}
+}
+
+// Finding is a weakness found in a container's configuration by an audit check.
+type Finding struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// auditCheck inspects a container and returns the findings it raises.
+type auditCheck func(container Container) []Finding
+
+// auditChecks are the built-in checks evaluated against every container
+var auditChecks = []auditCheck{
+	checkPrivileged,
+	checkHostNamespaces,
+	checkHostPathMounts,
+	checkRootUser,
+	checkWritableRootfs,
+	checkSecurityProfiles,
+	checkSeccomp,
+	checkCapabilities,
+}
+
+// hostNamespaceSeverities are the namespaces worth reporting when shared with the host.
+// Most containers share the user, cgroup and time namespaces with the host.
+var hostNamespaceSeverities = map[string]Severity{
+	"mnt": SeverityCritical,
+	"pid": SeverityHigh,
+	"net": SeverityHigh,
+	"ipc": SeverityMedium,
+	"uts": SeverityLow,
+}
+
+// sensitiveHostPaths are paths that give control over the host or its runtimes when
+// they, or a directory above them, are mounted into a container. Host paths are resolved
+// through the host's mountinfo, where /var/run shows up as /run.
+var sensitiveHostPaths = map[string]Severity{
+	"/run/docker.sock":                SeverityCritical,
+	"/run/containerd/containerd.sock": SeverityCritical,
+	"/run/crio/crio.sock":             SeverityCritical,
+	"/var/run/docker.sock":            SeverityCritical,
+	"/var/run/crio/crio.sock":         SeverityCritical,
+	"/var/lib/kubelet":                SeverityHigh,
+	"/var/lib/docker":                 SeverityHigh,
+	"/etc/kubernetes":                 SeverityHigh,
+	"/proc/sysrq-trigger":             SeverityHigh,
+	"/dev/mem":                        SeverityHigh,
+	"/host":                           SeverityHigh,
+}
+
+var (
+	hostCapabilitiesOnce sync.Once
+	// Capabilities in the bounding set of Tachyon itself, which runs as root on the host
+	hostCapabilities []string
+)
+
+// auditContainer evaluates the built-in checks against a container and returns its
+// findings, most severe first.
+func auditContainer(container Container) []Finding {
+	var findings []Finding
+	for _, check := range auditChecks {
+		findings = append(findings, check(container)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity > findings[j].Severity
+	})
+
+	return findings
+}
+
+// highestSeverity returns the severity of the most severe finding.
+func highestSeverity(findings []Finding) Severity {
+	severity := SeverityNone
+	for _, finding := range findings {
+		if finding.Severity > severity {
+			severity = finding.Severity
+		}
+	}
+	return severity
+}
+
+// isPrivileged reports whether the container holds every capability of the host, which
+// is what privileged containers are given.
+func isPrivileged(container Container) bool {
+	hostCapabilitiesOnce.Do(func() {
+		if status, err := readSecurityStatus(os.Getpid()); err == nil {
+			hostCapabilities = status.Bounding
+		}
+	})
+	if len(hostCapabilities) == 0 {
+		return false
+	}
+
+	bounding := make(map[string]bool)
+	for _, capability := range container.Security.Bounding {
+		bounding[capability] = true
+	}
+	for _, capability := range hostCapabilities {
+		if !bounding[capability] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPrivileged flags containers running with every capability of the host.
+func checkPrivileged(container Container) []Finding {
+	if !isPrivileged(container) {
+		return nil
+	}
+	return []Finding{{
+		Check:    "privileged",
+		Severity: SeverityCritical,
+		Message:  "container is privileged and holds every capability of the host",
+	}}
+}
+
+// checkHostNamespaces flags namespaces shared with the host, such as hostNetwork or hostPID pods.
+func checkHostNamespaces(container Container) []Finding {
+	var findings []Finding
+	for _, namespace := range container.Namespaces {
+		severity, ok := hostNamespaceSeverities[namespace.Type]
+		if !ok || !namespace.SharedWithHost {
+			continue
+		}
+		findings = append(findings, Finding{
+			Check:    "host-namespace",
+			Severity: severity,
+			Message:  fmt.Sprintf("shares the %s namespace with the host", namespace.Type),
+		})
+	}
+	return findings
+}
+
+// checkHostPathMounts flags the host's root filesystem and sensitive host paths mounted
+// into the container.
+func checkHostPathMounts(container Container) []Finding {
+	var findings []Finding
+	for _, mount := range container.Mounts {
+		// The container's own root filesystem is mounted from the host too
+		if mount.HostPath == "" || mount.Target == "/" {
+			continue
+		}
+
+		if mount.HostPath == "/" {
+			findings = append(findings, Finding{
+				Check:    "host-path-mount",
+				Severity: SeverityCritical,
+				Message:  fmt.Sprintf("the host's root filesystem is mounted at %s", mount.Target),
+			})
+			continue
+		}
+
+		exposed, severity, ok := exposedHostPath(mount.HostPath)
+		if !ok {
+			// Host directories are commonly mounted at /host
+			severity, ok = sensitiveHostPaths[mount.Target]
+			exposed = mount.HostPath
+		}
+		if !ok {
+			continue
+		}
+
+		message := fmt.Sprintf("%s is mounted from the host at %s", mount.HostPath, mount.Target)
+		if exposed != mount.HostPath {
+			message += fmt.Sprintf(", exposing %s", exposed)
+		}
+		findings = append(findings, Finding{
+			Check:    "host-path-mount",
+			Severity: severity,
+			Message:  message,
+		})
+	}
+	return findings
+}
+
+// exposedHostPath returns the most sensitive host path that a mounted host path is or
+// contains.
+func exposedHostPath(hostPath string) (string, Severity, bool) {
+	var exposed string
+	var highest Severity
+	for path, severity := range sensitiveHostPaths {
+		if path != hostPath && !strings.HasPrefix(path, strings.TrimSuffix(hostPath, "/")+"/") {
+			continue
+		}
+		if severity > highest || (severity == highest && path < exposed) {
+			exposed, highest = path, severity
+		}
+	}
+	return exposed, highest, exposed != ""
+}
+
+// checkRootUser flags containers whose init process runs as root, which is root on the
+// host unless a user namespace remaps it.
+func checkRootUser(container Container) []Finding {
+	if !container.Security.RunsAsRoot() {
+		return nil
+	}
+	if !container.Security.RootOnHost() {
+		return []Finding{{
+			Check:    "root-user",
+			Severity: SeverityLow,
+			Message:  "runs as root, remapped to an unprivileged user on the host",
+		}}
+	}
+	return []Finding{{
+		Check:    "root-user",
+		Severity: SeverityMedium,
+		Message:  "runs as root, which is root on the host",
+	}}
+}
+
+// checkWritableRootfs flags containers whose root filesystem can be written to.
+func checkWritableRootfs(container Container) []Finding {
+	if container.ReadOnlyRootfs {
+		return nil
+	}
+	return []Finding{{
+		Check:    "writable-rootfs",
+		Severity: SeverityLow,
+		Message:  "root filesystem is writable",
+	}}
+}
+
+// checkSecurityProfiles flags containers confined by neither AppArmor nor SELinux.
+func checkSecurityProfiles(container Container) []Finding {
+	for _, profile := range container.SecurityProfiles {
+		if profile != "" && !strings.HasPrefix(profile, "unconfined") {
+			return nil
+		}
+	}
+	return []Finding{{
+		Check:    "no-lsm-profile",
+		Severity: SeverityMedium,
+		Message:  "not confined by an AppArmor or SELinux profile",
+	}}
+}
+
+// checkSeccomp flags containers running without a seccomp filter.
+func checkSeccomp(container Container) []Finding {
+	if container.Security.Seccomp != "disabled" {
+		return nil
+	}
+	return []Finding{{
+		Check:    "no-seccomp",
+		Severity: SeverityMedium,
+		Message:  "runs without a seccomp filter",
+	}}
+}
+
+// checkCapabilities flags dangerous capabilities in the effective set. Privileged
+// containers are already reported as a whole.
+func checkCapabilities(container Container) []Finding {
+	if isPrivileged(container) {
+		return nil
+	}
+
+	var sensitive []string
+	for _, capability := range container.Security.Effective {
+		if dangerousCapabilities[capability] {
+			sensitive = append(sensitive, capability)
+		}
+	}
+	if len(sensitive) == 0 {
+		return nil
+	}
+
+	severity := SeverityHigh
+	for _, capability := range sensitive {
+		if capability == "CAP_SYS_ADMIN" || capability == "CAP_SYS_MODULE" {
+			severity = SeverityCritical
+		}
+	}
+	return []Finding{{
+		Check:    "sensitive-capabilities",
+		Severity: severity,
+		Message:  fmt.Sprintf("holds %s", strings.Join(sensitive, ", ")),
+	}}
+}
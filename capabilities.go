@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// capabilityNames are the Linux capabilities indexed by their bit number
+var capabilityNames = []string{
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_DAC_READ_SEARCH",
+	"CAP_FOWNER",
+	"CAP_FSETID",
+	"CAP_KILL",
+	"CAP_SETGID",
+	"CAP_SETUID",
+	"CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN",
+	"CAP_NET_RAW",
+	"CAP_IPC_LOCK",
+	"CAP_IPC_OWNER",
+	"CAP_SYS_MODULE",
+	"CAP_SYS_RAWIO",
+	"CAP_SYS_CHROOT",
+	"CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT",
+	"CAP_SYS_ADMIN",
+	"CAP_SYS_BOOT",
+	"CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE",
+	"CAP_SYS_TIME",
+	"CAP_SYS_TTY_CONFIG",
+	"CAP_MKNOD",
+	"CAP_LEASE",
+	"CAP_AUDIT_WRITE",
+	"CAP_AUDIT_CONTROL",
+	"CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE",
+	"CAP_MAC_ADMIN",
+	"CAP_SYSLOG",
+	"CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND",
+	"CAP_AUDIT_READ",
+	"CAP_PERFMON",
+	"CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// dangerousCapabilities are the capabilities that allow a container to escape to or
+// tamper with the host
+var dangerousCapabilities = map[string]bool{
+	"CAP_DAC_READ_SEARCH": true,
+	"CAP_NET_ADMIN":       true,
+	"CAP_SYS_MODULE":      true,
+	"CAP_SYS_RAWIO":       true,
+	"CAP_SYS_PTRACE":      true,
+	"CAP_SYS_ADMIN":       true,
+	"CAP_SYS_BOOT":        true,
+	"CAP_MAC_OVERRIDE":    true,
+	"CAP_MAC_ADMIN":       true,
+	"CAP_BPF":             true,
+}
+
+// seccompModes are the seccomp modes reported in /proc/<pid>/status
+var seccompModes = []string{"disabled", "strict", "filter"}
+
+type SecurityStatus struct {
+	Effective      []string `json:"effective"`
+	Permitted      []string `json:"permitted"`
+	Bounding       []string `json:"bounding"`
+	Ambient        []string `json:"ambient"`
+	Seccomp        string   `json:"seccomp"`
+	SeccompFilters int      `json:"seccomp_filters"`
+	NoNewPrivs     bool     `json:"no_new_privs"`
+	UID            int      `json:"uid"`      // effective UID inside the container
+	GID            int      `json:"gid"`      // effective GID inside the container
+	HostUID        int      `json:"host_uid"` // effective UID as seen from the host
+	HostGID        int      `json:"host_gid"` // effective GID as seen from the host
+}
+
+// RunsAsRoot reports whether the container's init process runs as root inside the container.
+func (s SecurityStatus) RunsAsRoot() bool {
+	return s.UID == 0
+}
+
+// RootOnHost reports whether the container's init process runs as root on the host.
+func (s SecurityStatus) RootOnHost() bool {
+	return s.HostUID == 0
+}
+
+// getContainerSecurityStatus reads the capabilities, seccomp mode, no_new_privs flag and
+// credentials of the container's init process. /proc reports the credentials as host
+// IDs, so they are mapped back through the container's user namespace.
+func (c *Container) getContainerSecurityStatus() (SecurityStatus, error) {
+	status, err := readSecurityStatus(c.PID)
+	if err != nil {
+		return SecurityStatus{}, err
+	}
+
+	status.UID = containerID(status.HostUID, c.UIDMap)
+	status.GID = containerID(status.HostGID, c.GIDMap)
+
+	return status, nil
+}
+
+// readSecurityStatus reads the security status of a process from /proc/<pid>/status.
+// The UID and GID are the host IDs, as seen from Tachyon's user namespace.
+func readSecurityStatus(pid int) (SecurityStatus, error) {
+	file, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return SecurityStatus{}, err
+	}
+	defer file.Close()
+
+	var status SecurityStatus
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "CapEff":
+			status.Effective, err = decodeCapabilities(value)
+		case "CapPrm":
+			status.Permitted, err = decodeCapabilities(value)
+		case "CapBnd":
+			status.Bounding, err = decodeCapabilities(value)
+		case "CapAmb":
+			status.Ambient, err = decodeCapabilities(value)
+		case "Seccomp":
+			mode, _ := strconv.Atoi(value)
+			status.Seccomp = "unknown"
+			if mode >= 0 && mode < len(seccompModes) {
+				status.Seccomp = seccompModes[mode]
+			}
+		case "Seccomp_filters":
+			status.SeccompFilters, _ = strconv.Atoi(value)
+		case "NoNewPrivs":
+			status.NoNewPrivs = value == "1"
+		case "Uid", "Gid":
+			// Real, effective, saved and filesystem IDs, in that order
+			fields := strings.Fields(value)
+			if len(fields) < 2 {
+				continue
+			}
+			id, _ := strconv.Atoi(fields[1])
+			if key == "Uid" {
+				status.HostUID, status.UID = id, id
+			} else {
+				status.HostGID, status.GID = id, id
+			}
+		}
+		if err != nil {
+			return SecurityStatus{}, fmt.Errorf("malformed %s for pid %d: %w", key, pid, err)
+		}
+	}
+
+	return status, scanner.Err()
+}
+
+// decodeCapabilities decodes a hexadecimal capability mask into capability names.
+func decodeCapabilities(mask string) ([]string, error) {
+	bits, err := strconv.ParseUint(mask, 16, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var capabilities []string
+	for bit := 0; bit < 64; bit++ {
+		if bits&(1<<uint(bit)) == 0 {
+			continue
+		}
+		if bit < len(capabilityNames) {
+			capabilities = append(capabilities, capabilityNames[bit])
+		} else {
+			capabilities = append(capabilities, fmt.Sprintf("CAP_%d", bit))
+		}
+	}
+
+	return capabilities, nil
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// cgroupRoot is where the cgroup hierarchy is mounted on the host
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupV1MemoryUnlimited is the threshold above which a cgroup v1 memory limit means unlimited
+const cgroupV1MemoryUnlimited = 1 << 62
+
+// Filesystem magic numbers used to tell the cgroup hierarchy modes apart
+const (
+	cgroup2SuperMagic = 0x63677270
+	tmpfsMagic        = 0x01021994
+)
+
+// cgroupMode is the layout of the cgroup hierarchy on the host.
+type cgroupMode int
+
+const (
+	cgroupModeUnknown cgroupMode = iota
+	// cgroupModeLegacy has only cgroup v1 controller hierarchies
+	cgroupModeLegacy
+	// cgroupModeHybrid has the controllers on cgroup v1 and an empty unified hierarchy
+	cgroupModeHybrid
+	// cgroupModeUnified has every controller on cgroup v2
+	cgroupModeUnified
+)
+
+var (
+	// Hierarchy mode of the host, detected once
+	hostCgroupMode     cgroupMode
+	hostCgroupModeOnce sync.Once
+)
+
+// detectCgroupMode determines the cgroup hierarchy mode from the filesystem mounted at cgroupRoot.
+func detectCgroupMode() cgroupMode {
+	hostCgroupModeOnce.Do(func() {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(cgroupRoot, &stat); err != nil {
+			return
+		}
+
+		switch stat.Type {
+		case cgroup2SuperMagic:
+			hostCgroupMode = cgroupModeUnified
+		case tmpfsMagic:
+			hostCgroupMode = cgroupModeLegacy
+			var unified syscall.Statfs_t
+			err := syscall.Statfs(filepath.Join(cgroupRoot, "unified"), &unified)
+			if err == nil && unified.Type == cgroup2SuperMagic {
+				hostCgroupMode = cgroupModeHybrid
+			}
+		}
+	})
+
+	return hostCgroupMode
+}
+
+// readCgroupUsage reads the resource usage of a process's cgroup on either hierarchy mode.
+// In hybrid mode the controllers live on cgroup v1, so it is read like a legacy host.
+func readCgroupUsage(pid int) (ResourceUsage, error) {
+	switch detectCgroupMode() {
+	case cgroupModeUnified:
+		dir, err := cgroupV2Path(pid)
+		if err != nil {
+			return ResourceUsage{}, err
+		}
+		return readCgroupV2Usage(dir)
+	case cgroupModeLegacy, cgroupModeHybrid:
+		paths, err := cgroupV1Paths(pid)
+		if err != nil {
+			return ResourceUsage{}, err
+		}
+		return readCgroupV1Usage(paths)
+	default:
+		return ResourceUsage{}, errors.New("no cgroup hierarchy found")
+	}
+}
+
+// readCgroupLimits reads the resource limits of a process's cgroup on either hierarchy mode.
+func readCgroupLimits(pid int) (ResourceLimits, error) {
+	switch detectCgroupMode() {
+	case cgroupModeUnified:
+		dir, err := cgroupV2Path(pid)
+		if err != nil {
+			return ResourceLimits{}, err
+		}
+		return readCgroupV2Limits(dir)
+	case cgroupModeLegacy, cgroupModeHybrid:
+		paths, err := cgroupV1Paths(pid)
+		if err != nil {
+			return ResourceLimits{}, err
+		}
+		return readCgroupV1Limits(paths)
+	default:
+		return ResourceLimits{}, errors.New("no cgroup hierarchy found")
+	}
+}
+
+// cgroupProcs lists the PIDs of every process in a process's cgroup, including its child cgroups.
+func cgroupProcs(pid int) ([]int, error) {
+	var dir string
+	switch detectCgroupMode() {
+	case cgroupModeUnified:
+		path, err := cgroupV2Path(pid)
+		if err != nil {
+			return nil, err
+		}
+		dir = path
+	case cgroupModeLegacy, cgroupModeHybrid:
+		paths, err := cgroupV1Paths(pid)
+		if err != nil {
+			return nil, err
+		}
+		// Every process is in a pids or memory cgroup when those controllers are enabled
+		dir = paths["pids"]
+		if dir == "" {
+			dir = paths["memory"]
+		}
+	}
+	if dir == "" {
+		return nil, errors.New("no cgroup hierarchy found")
+	}
+
+	var pids []int
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || entry.Name() != "cgroup.procs" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, field := range strings.Fields(string(content)) {
+			if pid, err := strconv.Atoi(field); err == nil {
+				pids = append(pids, pid)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pids, nil
+}
+
+// cgroupV2Path resolves the cgroup v2 directory of a process from /proc/<pid>/cgroup.
+func cgroupV2Path(pid int) (string, error) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+
+	// The unified hierarchy is the entry with ID 0 and no controllers, e.g. 0::/kubepods/...
+	for _, line := range strings.Split(string(content), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return filepath.Join(cgroupRoot, path), nil
+		}
+	}
+
+	return "", fmt.Errorf("no cgroup v2 entry for pid %d", pid)
+}
+
+// readCgroupV2Usage reads the resource usage of every process in a cgroup v2 directory.
+func readCgroupV2Usage(dir string) (ResourceUsage, error) {
+	usage := ResourceUsage{MemoryUsage: make(map[string]int)}
+
+	cpuStat, err := readCgroupKeyValues(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return ResourceUsage{}, fmt.Errorf("error reading cpu.stat: %w", err)
+	}
+	usage.CPUTime = cpuStat["usage_usec"]
+
+	current, err := readCgroupUint(filepath.Join(dir, "memory.current"))
+	if err != nil {
+		return ResourceUsage{}, fmt.Errorf("error reading memory.current: %w", err)
+	}
+	usage.MemoryUsage["Current"] = int(current / 1024)
+
+	memoryStat, err := readCgroupKeyValues(filepath.Join(dir, "memory.stat"))
+	if err != nil {
+		return ResourceUsage{}, fmt.Errorf("error reading memory.stat: %w", err)
+	}
+	usage.MemoryUsage["Anon"] = int(memoryStat["anon"] / 1024)
+	usage.MemoryUsage["File"] = int(memoryStat["file"] / 1024)
+
+	// Swap accounting can be disabled on the host
+	if swap, err := readCgroupUint(filepath.Join(dir, "memory.swap.current")); err == nil {
+		usage.SwapUsage = int(swap / 1024)
+	}
+
+	// io.stat has one line per device, e.g. "8:0 rbytes=1 wbytes=2 rios=3 wios=4 ..."
+	ioStat, err := os.ReadFile(filepath.Join(dir, "io.stat"))
+	if err == nil {
+		for _, line := range strings.Split(string(ioStat), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			for _, field := range fields[1:] {
+				key, value, _ := strings.Cut(field, "=")
+				n, err := strconv.ParseUint(value, 10, 64)
+				if err != nil {
+					continue
+				}
+				switch key {
+				case "rbytes":
+					usage.IOReadBytes += n
+				case "wbytes":
+					usage.IOWriteBytes += n
+				}
+			}
+		}
+	}
+
+	if pids, err := readCgroupUint(filepath.Join(dir, "pids.current")); err == nil {
+		usage.Pids = int(pids)
+	}
+
+	return usage, nil
+}
+
+// readCgroupV2Limits reads the CPU, memory and IO limits from cpu.max, memory.max and io.max.
+// Unlimited resources are left at zero.
+func readCgroupV2Limits(dir string) (ResourceLimits, error) {
+	var limits ResourceLimits
+
+	// cpu.max holds "$QUOTA $PERIOD" where the quota can be "max"
+	cpuMax, err := os.ReadFile(filepath.Join(dir, "cpu.max"))
+	if err != nil {
+		return ResourceLimits{}, fmt.Errorf("error reading cpu.max: %w", err)
+	}
+	if fields := strings.Fields(string(cpuMax)); len(fields) == 2 {
+		quota, quotaErr := strconv.ParseUint(fields[0], 10, 64)
+		period, periodErr := strconv.ParseUint(fields[1], 10, 64)
+		if quotaErr == nil && periodErr == nil && period > 0 {
+			limits.CPULimit = float64(quota) / float64(period)
+		}
+	}
+
+	// memory.max holds either a byte count or "max"
+	if memoryMax, err := readCgroupUint(filepath.Join(dir, "memory.max")); err == nil {
+		limits.MemoryLimit = int(memoryMax / 1024)
+	}
+
+	// io.max has one line per throttled device, e.g. "8:0 rbps=1048576 wbps=max riops=max wiops=max"
+	ioMax, err := os.ReadFile(filepath.Join(dir, "io.max"))
+	if err == nil {
+		var rates []uint64
+		for _, line := range strings.Split(string(ioMax), "\n") {
+			for _, field := range strings.Fields(line) {
+				key, value, _ := strings.Cut(field, "=")
+				if key != "rbps" && key != "wbps" {
+					continue
+				}
+				if rate, err := strconv.ParseUint(value, 10, 64); err == nil {
+					rates = append(rates, rate)
+				}
+			}
+		}
+		limits.DiskIOLimit = diskIOLimit(rates)
+	}
+
+	return limits, nil
+}
+
+// readCgroupV1Limits reads the CPU, memory and IO limits from the cpu, memory and blkio
+// controllers of a cgroup v1 hierarchy. Unlimited resources are left at zero.
+func readCgroupV1Limits(paths map[string]string) (ResourceLimits, error) {
+	var limits ResourceLimits
+
+	// A quota of -1 means the CPU is not limited
+	quota, err := os.ReadFile(filepath.Join(paths["cpu"], "cpu.cfs_quota_us"))
+	if err != nil {
+		return ResourceLimits{}, fmt.Errorf("error reading cpu.cfs_quota_us: %w", err)
+	}
+	period, err := readCgroupUint(filepath.Join(paths["cpu"], "cpu.cfs_period_us"))
+	if err != nil {
+		return ResourceLimits{}, fmt.Errorf("error reading cpu.cfs_period_us: %w", err)
+	}
+	if quota, err := strconv.ParseInt(strings.TrimSpace(string(quota)), 10, 64); err == nil && quota > 0 && period > 0 {
+		limits.CPULimit = float64(quota) / float64(period)
+	}
+
+	// An unlimited memory cgroup reports a value close to the maximum int64
+	if memoryLimit, err := readCgroupUint(filepath.Join(paths["memory"], "memory.limit_in_bytes")); err == nil && memoryLimit < cgroupV1MemoryUnlimited {
+		limits.MemoryLimit = int(memoryLimit / 1024)
+	}
+
+	// The throttle files have one line per throttled device, e.g. "8:0 1048576"
+	var rates []uint64
+	for _, name := range []string{"blkio.throttle.read_bps_device", "blkio.throttle.write_bps_device"} {
+		content, err := os.ReadFile(filepath.Join(paths["blkio"], name))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				continue
+			}
+			if rate, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+				rates = append(rates, rate)
+			}
+		}
+	}
+	limits.DiskIOLimit = diskIOLimit(rates)
+
+	return limits, nil
+}
+
+// diskIOLimit returns the most restrictive of a set of byte rate limits in MB/s, rounded up
+// so that a limit below 1 MB/s is not shown as unlimited.
+func diskIOLimit(rates []uint64) int {
+	var lowest uint64
+	for _, rate := range rates {
+		if rate > 0 && (lowest == 0 || rate < lowest) {
+			lowest = rate
+		}
+	}
+	return int((lowest + 1e6 - 1) / 1e6)
+}
+
+// cgroupV1Paths resolves the cgroup v1 directory of each controller of a process from
+// /proc/<pid>/cgroup, e.g. 4:cpu,cpuacct:/kubepods/... maps both cpu and cpuacct.
+func cgroupV1Paths(pid int) (map[string]string, error) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]string)
+	for _, line := range strings.Split(string(content), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 || parts[1] == "" {
+			continue
+		}
+
+		// Each controller is mounted (or symlinked) under its own name
+		for _, controller := range strings.Split(parts[1], ",") {
+			if strings.HasPrefix(controller, "name=") {
+				continue
+			}
+			paths[controller] = filepath.Join(cgroupRoot, controller, parts[2])
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no cgroup v1 entries for pid %d", pid)
+	}
+
+	return paths, nil
+}
+
+// readCgroupV1Usage reads the resource usage of a cgroup from the cpuacct, memory,
+// blkio and pids controllers of a cgroup v1 hierarchy.
+func readCgroupV1Usage(paths map[string]string) (ResourceUsage, error) {
+	usage := ResourceUsage{MemoryUsage: make(map[string]int)}
+
+	cpuTime, err := readCgroupUint(filepath.Join(paths["cpuacct"], "cpuacct.usage"))
+	if err != nil {
+		return ResourceUsage{}, fmt.Errorf("error reading cpuacct.usage: %w", err)
+	}
+	usage.CPUTime = cpuTime / 1000
+
+	current, err := readCgroupUint(filepath.Join(paths["memory"], "memory.usage_in_bytes"))
+	if err != nil {
+		return ResourceUsage{}, fmt.Errorf("error reading memory.usage_in_bytes: %w", err)
+	}
+	usage.MemoryUsage["Current"] = int(current / 1024)
+
+	// The total_ entries include the memory of child cgroups
+	memoryStat, err := readCgroupKeyValues(filepath.Join(paths["memory"], "memory.stat"))
+	if err != nil {
+		return ResourceUsage{}, fmt.Errorf("error reading memory.stat: %w", err)
+	}
+	usage.MemoryUsage["Anon"] = int(memoryStat["total_rss"] / 1024)
+	usage.MemoryUsage["File"] = int(memoryStat["total_cache"] / 1024)
+	usage.SwapUsage = int(memoryStat["total_swap"] / 1024)
+
+	// blkio.throttle.io_service_bytes has lines like "8:0 Read 1234" per device
+	ioServiceBytes, err := os.ReadFile(filepath.Join(paths["blkio"], "blkio.throttle.io_service_bytes"))
+	if err == nil {
+		for _, line := range strings.Split(string(ioServiceBytes), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 3 {
+				continue
+			}
+			n, err := strconv.ParseUint(fields[2], 10, 64)
+			if err != nil {
+				continue
+			}
+			switch fields[1] {
+			case "Read":
+				usage.IOReadBytes += n
+			case "Write":
+				usage.IOWriteBytes += n
+			}
+		}
+	}
+
+	if pids, err := readCgroupUint(filepath.Join(paths["pids"], "pids.current")); err == nil {
+		usage.Pids = int(pids)
+	}
+
+	return usage, nil
+}
+
+// readCgroupUint reads a cgroup file holding a single unsigned integer.
+func readCgroupUint(path string) (uint64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// readCgroupKeyValues reads a flat keyed cgroup file such as cpu.stat or memory.stat.
+func readCgroupKeyValues(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		values[fields[0]] = value
+	}
+
+	return values, scanner.Err()
+}
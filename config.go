@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config holds the user-configurable settings for Tachyon.
+type Config struct {
+	// Runtime selects the backend containers are discovered from: runc, docker, cri or fake
+	Runtime string `json:"runtime"`
+	// RuncRoots lists the runc state directories scanned for containers
+	RuncRoots []string `json:"runc_roots"`
+	// DockerSocket is the Docker engine API socket used by the docker runtime
+	DockerSocket string `json:"docker_socket"`
+	// CRIEndpoint is the CRI runtime endpoint used by the cri runtime
+	CRIEndpoint string `json:"cri_endpoint"`
+	// PerCoreCPU reports CPU usage relative to a single core, so a busy container can exceed 100%
+	PerCoreCPU bool `json:"per_core_cpu"`
+	// IncludeLoopback counts loopback traffic in the network usage
+	IncludeLoopback bool `json:"include_loopback"`
+	// HistoryLength is the number of samples of metric history kept per container
+	HistoryLength int `json:"history_length"`
+	// ShowPseudoMounts lists pseudo filesystems such as proc, sysfs and cgroup among the mounts
+	ShowPseudoMounts bool `json:"show_pseudo_mounts"`
+	// Rules are user-defined policy rules evaluated against every container
+	Rules []PolicyRule `json:"rules"`
+	// SecretPatterns are regular expressions matched against environment variable names
+	// and values to decide which values are masked
+	SecretPatterns []string `json:"secret_patterns"`
+}
+
+// defaultRuncRoots covers the state roots used by containerd (k8s.io, default and moby
+// namespaces), Docker and CRI-O.
+var defaultRuncRoots = []string{
+	"/run/containerd/runc/k8s.io",
+	"/run/containerd/runc/default",
+	"/run/containerd/runc/moby",
+	"/run/docker/runtime-runc/moby",
+	"/run/runc",
+}
+
+// config holds the settings in effect for this run
+var config = Config{
+	Runtime:        "runc",
+	RuncRoots:      defaultRuncRoots,
+	DockerSocket:   "/var/run/docker.sock",
+	CRIEndpoint:    "unix:///run/containerd/containerd.sock",
+	HistoryLength:  120,
+	SecretPatterns: defaultSecretPatterns,
+}
+
+// stringList is a flag.Value that can be passed multiple times or as a comma-separated list.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*s = append(*s, v)
+		}
+	}
+	return nil
+}
+
+// loadConfig parses the command line flags and the optional config file into config.
+// Flags take precedence over values from the config file.
+func loadConfig(args []string) error {
+	fs := flag.NewFlagSet("tachyon", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to a JSON config file")
+	runtimeName := fs.String("runtime", "", "container runtime backend: runc, docker, cri or fake")
+	var roots stringList
+	fs.Var(&roots, "root", "runc state root to scan for containers (repeatable)")
+	dockerSocket := fs.String("docker-socket", "", "Docker engine API socket")
+	criEndpoint := fs.String("cri-endpoint", "", "CRI runtime endpoint")
+	includeLoopback := fs.Bool("include-loopback", false, "count loopback traffic in the network usage")
+	historyLength := fs.Int("history", 0, "number of metric samples kept per container")
+	showPseudoMounts := fs.Bool("show-pseudo-mounts", false, "list pseudo filesystems such as proc and cgroup among the mounts")
+	perCoreCPU := fs.Bool("per-core-cpu", false, "report CPU usage relative to a single core instead of all CPUs")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *configPath != "" {
+		content, err := os.ReadFile(*configPath)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := json.Unmarshal(content, &config); err != nil {
+			return fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
+
+	if *runtimeName != "" {
+		config.Runtime = *runtimeName
+	}
+	if len(roots) > 0 {
+		config.RuncRoots = roots
+	}
+	if *dockerSocket != "" {
+		config.DockerSocket = *dockerSocket
+	}
+	if *criEndpoint != "" {
+		config.CRIEndpoint = *criEndpoint
+	}
+	if *perCoreCPU {
+		config.PerCoreCPU = true
+	}
+	if *includeLoopback {
+		config.IncludeLoopback = true
+	}
+	if *showPseudoMounts {
+		config.ShowPseudoMounts = true
+	}
+	if *historyLength > 0 {
+		config.HistoryLength = *historyLength
+	}
+
+	if config.Runtime == "runc" && len(config.RuncRoots) == 0 {
+		return errors.New("no runc roots configured")
+	}
+
+	return nil
+}
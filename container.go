@@ -1,17 +1,16 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"os"
-	"os/exec"
-	"strconv"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/shirou/gopsutil/net"
 	"github.com/shirou/gopsutil/process"
 )
 
@@ -22,41 +21,122 @@ var (
 	containerCache = make(map[string]Container)
 	// Timestamp for the last time the data was refreshed
 	lastRefreshed time.Time
+	// Previous CPU time sample of each container, used to compute CPU percentages
+	cpuSamples = make(map[string]cpuSample)
+	// Previous network counters of each container, used to compute throughput rates
+	networkSamples = make(map[string]NetworkUsage)
+	// Previous IO counters of each container, used to compute disk IO rates
+	ioSamples = make(map[string]ioSample)
+	// Previous samples of each container's processes, used to compute per-process CPU percentages
+	processSamples = make(map[string][]ProcessInfo)
 )
 
 type Container struct {
-	OciVersion       string            `json:"ociVersion"`
-	ID               string            `json:"id"`
-	PID              int               `json:"pid"`
-	Status           string            `json:"status"`
-	Bundle           string            `json:"bundle"`
-	RootFS           string            `json:"rootfs"`
-	Created          string            `json:"created"`
-	Annotations      map[string]string `json:"annotations"`
-	Owner            string            `json:"owner"`
-	OpenFiles        []LsofOutput      `json:"open_files"`
-	NetworkUsage     NetworkUsage      `json:"network_usage"`
-	MountedVolumes   []string          `json:"mounted_volumes"`
-	ExposedPorts     []int             `json:"exposed_ports"`
-	TopProcesses     []ProcessInfo     `json:"top_processes"`
-	SecurityProfiles []string          `json:"security_profiles"`
-	StartCommand     string            `json:"start_command"`
-	ResourceLimits   ResourceLimits    `json:"resource_limits"`
+	OciVersion       string             `json:"ociVersion"`
+	ID               string             `json:"id"`
+	PID              int                `json:"pid"`
+	Status           string             `json:"status"`
+	Bundle           string             `json:"bundle"`
+	Spec             *ociSpec           `json:"spec"`
+	SpecError        string             `json:"spec_error"` // why the bundle spec could not be read
+	RootFS           string             `json:"rootfs"`
+	Created          string             `json:"created"`
+	Annotations      map[string]string  `json:"annotations"`
+	Owner            string             `json:"owner"`
+	Namespace        string             `json:"namespace"`
+	Root             string             `json:"root"`
+	Image            string             `json:"image"`
+	CgroupPaths      map[string]string  `json:"cgroup_paths"`
+	NamespacePaths   map[string]string  `json:"namespace_paths"`
+	Namespaces       []Namespace        `json:"namespaces"`
+	UIDMap           []IDMapping        `json:"uid_map"`
+	GIDMap           []IDMapping        `json:"gid_map"`
+	OpenFiles        []LsofOutput       `json:"open_files"`
+	NetworkUsage     NetworkUsage       `json:"network_usage"`
+	Interfaces       []NetworkInterface `json:"interfaces"`
+	Routes           []Route            `json:"routes"`
+	Mounts           []Mount            `json:"mounts"`
+	ReadOnlyRootfs   bool               `json:"readonly_rootfs"`
+	DiskUsage        []DiskUsage        `json:"disk_usage"`
+	Sockets          []Socket           `json:"sockets"`
+	TopProcesses     []ProcessInfo      `json:"top_processes"`
+	ProcessAlerts    []ProcessAlert     `json:"process_alerts"`
+	SecurityProfiles []string           `json:"security_profiles"`
+	Security         SecurityStatus     `json:"security"`
+	StartCommand     string             `json:"start_command"`
+	ResourceLimits   ResourceLimits     `json:"resource_limits"`
 	EnvVariables     []string
 	ResourceUsage    ResourceUsage
+	Findings         []Finding `json:"findings"`
+	Violations       []Finding `json:"violations"`
+	PopulateError    string    `json:"populate_error"` // why the container could not be inspected
 }
 
 type NetworkUsage struct {
-	ReceivedBytes    int `json:"received_bytes"`
-	TransmittedBytes int `json:"transmitted_bytes"`
+	ReceivedBytes    int              `json:"received_bytes"`
+	TransmittedBytes int              `json:"transmitted_bytes"`
+	ReceiveRate      float64          `json:"receive_rate"`  // in bytes per second
+	TransmitRate     float64          `json:"transmit_rate"` // in bytes per second
+	Interfaces       []InterfaceStats `json:"interfaces"`
+	SampledAt        time.Time        `json:"sampled_at"`
+}
+
+type InterfaceStats struct {
+	Name         string  `json:"name"`
+	RxBytes      uint64  `json:"rx_bytes"`
+	RxPackets    uint64  `json:"rx_packets"`
+	RxErrors     uint64  `json:"rx_errors"`
+	RxDropped    uint64  `json:"rx_dropped"`
+	TxBytes      uint64  `json:"tx_bytes"`
+	TxPackets    uint64  `json:"tx_packets"`
+	TxErrors     uint64  `json:"tx_errors"`
+	TxDropped    uint64  `json:"tx_dropped"`
+	ReceiveRate  float64 `json:"receive_rate"`  // in bytes per second
+	TransmitRate float64 `json:"transmit_rate"` // in bytes per second
+}
+
+type NetworkInterface struct {
+	Name      string   `json:"name"`
+	MTU       int      `json:"mtu"`
+	MAC       string   `json:"mac"`
+	Flags     string   `json:"flags"`
+	Addresses []string `json:"addresses"`
+}
+
+type Route struct {
+	Interface   string `json:"interface"`
+	Destination string `json:"destination"`
+	Gateway     string `json:"gateway"`
+	Metric      int    `json:"metric"`
+}
+
+type Socket struct {
+	Protocol      string `json:"protocol"`
+	LocalAddress  string `json:"local_address"`
+	RemoteAddress string `json:"remote_address"`
+	State         string `json:"state"`
+	Inode         uint64 `json:"inode"`
+	PID           int    `json:"pid"`
+	Process       string `json:"process"`
 }
 
 type ProcessInfo struct {
-	PID  int    `json:"pid"`
-	User string `json:"user"`
-	CPU  float64
-	MEM  float64
-	CMD  string `json:"cmd"`
+	PID         int    `json:"pid"`
+	PPID        int    `json:"ppid"`
+	NSPID       int    `json:"ns_pid"` // PID inside the container's PID namespace
+	User        string `json:"user"`
+	State       string `json:"state"`
+	Threads     int    `json:"threads"`
+	CPU         float64
+	MEM         float64
+	RSS         int       `json:"rss"`      // in kB
+	CPUTime     uint64    `json:"cpu_time"` // cumulative, in microseconds
+	StartTime   uint64    `json:"start_time"`
+	SampledAt   time.Time `json:"sampled_at"`
+	CMD         string    `json:"cmd"`
+	Exe         string    `json:"exe"`          // executable path inside the container
+	WritableExe bool      `json:"writable_exe"` // the executable lives in the container's writable layer
+	ExeChanged  time.Time `json:"exe_changed"`  // last status change of the executable, which touch cannot set back
 }
 
 type LsofOutput struct {
@@ -69,19 +149,51 @@ type LsofOutput struct {
 	SizeOff string `json:"size_off"`
 	Node    string `json:"node"`
 	Name    string `json:"name"`
+	Pos     int64  `json:"pos"`
+	Flags   string `json:"flags"`
 }
 
 type ResourceLimits struct {
 	CPULimit     float64 // in percentage or cores
 	MemoryLimit  int     // in kB
-	DiskIOLimit  int     // in IOPS or MB/s
-	NetworkLimit int     // in MB/s
+	DiskIOLimit  int     // in MB/s
+	NetworkLimit int     // in bytes per second
 }
 
 type ResourceUsage struct {
-	CPUUsage    float64        // in percentage
-	MemoryUsage map[string]int // in kB
-	SwapUsage   int
+	CPUUsage     float64        // in percentage, of all CPUs or of one core with PerCoreCPU
+	CPUCores     float64        // number of cores in use
+	CPUTime      uint64         // cumulative, in microseconds
+	MemoryUsage  map[string]int // in kB
+	SwapUsage    int            // in kB
+	IOReadBytes  uint64
+	IOWriteBytes uint64
+	IOReadRate   float64 // in bytes per second
+	IOWriteRate  float64 // in bytes per second
+	Pids         int
+	SampledAt    time.Time
+}
+
+// MemoryInUse returns the container's memory usage in kB, using the cgroup total when
+// available and the init process RSS otherwise.
+func (u ResourceUsage) MemoryInUse() int {
+	if memory, ok := u.MemoryUsage["Current"]; ok {
+		return memory
+	}
+	return u.MemoryUsage["RSS"]
+}
+
+// cpuSample is a container's cumulative CPU time at the moment it was read
+type cpuSample struct {
+	cpuTime   uint64
+	sampledAt time.Time
+}
+
+// ioSample is a container's cumulative IO counters at the moment they were read
+type ioSample struct {
+	readBytes  uint64
+	writeBytes uint64
+	sampledAt  time.Time
 }
 
 func StartCacheRefresh() {
@@ -94,7 +206,7 @@ func StartCacheRefresh() {
 				if err == nil {
 					cacheMutex.Lock()
 					for _, container := range containers {
-						containerCache[strconv.Itoa(container.PID)] = container
+						containerCache[container.ID] = container
 					}
 					lastRefreshed = time.Now()
 					cacheMutex.Unlock()
@@ -102,13 +214,26 @@ func StartCacheRefresh() {
 			}
 		}
 	}()
+
+	// Invalidate the cache as soon as the runtime reports a lifecycle change
+	events, err := containerRuntime.Events(context.Background())
+	if err != nil {
+		return
+	}
+	go func() {
+		for range events {
+			cacheMutex.Lock()
+			lastRefreshed = time.Time{}
+			cacheMutex.Unlock()
+		}
+	}()
 }
 
 // GetContainerByID retrieves container information by its ID.
-func GetContainerByID(pid string) (Container, error) {
+func GetContainerByID(id string) (Container, error) {
 	// Read-lock the cache to ensure safe access
 	cacheMutex.RLock()
-	container, exists := containerCache[pid]
+	container, exists := containerCache[id]
 	cacheMutex.RUnlock()
 
 	// Check if the container data exists and if the cache is still fresh
@@ -116,31 +241,39 @@ func GetContainerByID(pid string) (Container, error) {
 		return container, nil
 	}
 
-	pidInt, err := strconv.Atoi(pid)
-	if err != nil {
-		return Container{}, fmt.Errorf("invalid PID format: %w", err)
-	}
-
-	containers, err := GetContainers(false)
+	container, err := containerRuntime.Inspect(id)
 	if err != nil {
 		return Container{}, err
 	}
 
-	// Search for the container by PID and populate it if found
-	for _, container := range containers {
-		if container.PID == pidInt {
-			err := container.PopulateContainer()
-			if err != nil {
-				return Container{}, fmt.Errorf("failed to populate container: %w", err)
-			}
-			return container, nil
-		}
+	err = container.PopulateContainer()
+	if err != nil {
+		return Container{}, fmt.Errorf("failed to populate container: %w", err)
 	}
 
-	// Update the cache and the timestamp with the new data
+	// Update the cache with the new data
 	cacheMutex.Lock()
-	containerCache[pid] = container
-	lastRefreshed = time.Now()
+	container.Findings = auditContainer(container)
+	container.Violations = evaluatePolicies(container)
+	updateCPUUsage(&container)
+	updateNetworkRates(&container)
+	updateIORates(&container)
+	updateProcessCPU(&container)
+	updateProcessDrift(&container)
+	recordHistory(container)
+	containerCache[id] = container
+
+	// The container's namespaces may have changed, so every cached container is marked
+	// again, as a full refresh would
+	cached := make([]Container, 0, len(containerCache))
+	for _, other := range containerCache {
+		cached = append(cached, other)
+	}
+	for _, other := range cached {
+		markSharedNamespaces(&other, cached)
+		containerCache[other.ID] = other
+	}
+	container = containerCache[id]
 	cacheMutex.Unlock()
 
 	return container, nil
@@ -159,28 +292,31 @@ func GetContainers(populate bool) ([]Container, error) {
 		return cachedContainers, nil
 	}
 
-	// Execute runc to fetch information about running containers
-	out, err := exec.Command("sudo", "runc", "--root", "/run/containerd/runc/k8s.io", "list", "--format", "json").Output()
-	if err != nil {
-		return nil, fmt.Errorf("error executing runc command: %w", err)
-	}
-
-	if len(out) == 0 {
-		return nil, errors.New("runc output is empty")
-	}
-
-	var containers []Container
-	err = json.Unmarshal(out, &containers)
+	containers, err := containerRuntime.List()
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal the runc output: %w", err)
+		return nil, fmt.Errorf("failed to list containers from %s: %w", containerRuntime.Name(), err)
 	}
 
 	// Optionally populate container data
 	if populate {
 		for i, _ := range containers {
+			// Containers without a running process have nothing to inspect
+			if containers[i].PID <= 0 {
+				continue
+			}
+			// A container that exits or cannot be read while it is inspected is still
+			// listed, with the error, rather than failing the whole listing
 			err := containers[i].PopulateContainer()
 			if err != nil {
-				return nil, err
+				containers[i].PopulateError = err.Error()
+			}
+		}
+		pruneDiskUsage(containers)
+		for i := range containers {
+			markSharedNamespaces(&containers[i], containers)
+			if containers[i].populated() {
+				containers[i].Findings = auditContainer(containers[i])
+				containers[i].Violations = evaluatePolicies(containers[i])
 			}
 		}
 	}
@@ -188,8 +324,48 @@ func GetContainers(populate bool) ([]Container, error) {
 	// Update the cache and the timestamp with the new data
 	cacheMutex.Lock()
 	containerCache = make(map[string]Container)
-	for _, container := range containers {
-		containerCache[strconv.Itoa(container.PID)] = container
+	for i := range containers {
+		if populate && containers[i].populated() {
+			updateCPUUsage(&containers[i])
+			updateNetworkRates(&containers[i])
+			updateIORates(&containers[i])
+			updateProcessCPU(&containers[i])
+			updateProcessDrift(&containers[i])
+			recordHistory(containers[i])
+		}
+		containerCache[containers[i].ID] = containers[i]
+	}
+	// Forget the samples and history of containers that are gone
+	for id := range cpuSamples {
+		if _, ok := containerCache[id]; !ok {
+			delete(cpuSamples, id)
+		}
+	}
+	for id := range networkSamples {
+		if _, ok := containerCache[id]; !ok {
+			delete(networkSamples, id)
+		}
+	}
+	for id := range ioSamples {
+		if _, ok := containerCache[id]; !ok {
+			delete(ioSamples, id)
+		}
+	}
+	for id := range processSamples {
+		if _, ok := containerCache[id]; !ok {
+			delete(processSamples, id)
+		}
+	}
+	for id := range executableBaselines {
+		if _, ok := containerCache[id]; !ok {
+			delete(executableBaselines, id)
+			delete(processAlerts, id)
+		}
+	}
+	for id := range histories {
+		if _, ok := containerCache[id]; !ok {
+			delete(histories, id)
+		}
 	}
 	lastRefreshed = time.Now()
 	cacheMutex.Unlock()
@@ -197,10 +373,71 @@ func GetContainers(populate bool) ([]Container, error) {
 	return containers, nil
 }
 
+// populated reports whether the container was inspected successfully.
+func (c *Container) populated() bool {
+	return c.PID > 0 && c.PopulateError == ""
+}
+
+// updateCPUUsage computes a container's CPU percentage from the CPU time it consumed
+// since the previous sample divided by the wall-clock time in between, and records
+// the current sample. The caller must hold cacheMutex.
+func updateCPUUsage(container *Container) {
+	usage := &container.ResourceUsage
+
+	previous, ok := cpuSamples[container.ID]
+	cpuSamples[container.ID] = cpuSample{cpuTime: usage.CPUTime, sampledAt: usage.SampledAt}
+
+	// The first sample of a container has nothing to compare against
+	if !ok {
+		return
+	}
+
+	elapsed := usage.SampledAt.Sub(previous.sampledAt).Microseconds()
+	if elapsed <= 0 || usage.CPUTime < previous.cpuTime {
+		return
+	}
+
+	usage.CPUCores = float64(usage.CPUTime-previous.cpuTime) / float64(elapsed)
+	usage.CPUUsage = usage.CPUCores * 100
+	if !config.PerCoreCPU {
+		usage.CPUUsage /= float64(runtime.NumCPU())
+	}
+}
+
+// updateIORates computes a container's disk read and write rates from the bytes it
+// transferred since the previous sample, and records the current sample. The caller must
+// hold cacheMutex.
+func updateIORates(container *Container) {
+	usage := &container.ResourceUsage
+
+	previous, ok := ioSamples[container.ID]
+	ioSamples[container.ID] = ioSample{readBytes: usage.IOReadBytes, writeBytes: usage.IOWriteBytes, sampledAt: usage.SampledAt}
+
+	// The first sample of a container has nothing to compare against
+	if !ok {
+		return
+	}
+
+	elapsed := usage.SampledAt.Sub(previous.sampledAt).Seconds()
+	if elapsed <= 0 || usage.IOReadBytes < previous.readBytes || usage.IOWriteBytes < previous.writeBytes {
+		return
+	}
+
+	usage.IOReadRate = float64(usage.IOReadBytes-previous.readBytes) / elapsed
+	usage.IOWriteRate = float64(usage.IOWriteBytes-previous.writeBytes) / elapsed
+}
+
 // PopulateContainer retrieves information about the calling container by PID
 func (c *Container) PopulateContainer() error {
 	var err error
 
+	// An unreadable spec only leaves the spec section empty
+	c.Spec, err = c.getContainerSpec()
+	c.SpecError = ""
+	if err != nil {
+		c.SpecError = err.Error()
+	}
+
 	c.OpenFiles, err = c.getOpenFiles()
 	if err != nil {
 		return fmt.Errorf("failed to get open files: %w", err)
@@ -211,14 +448,36 @@ func (c *Container) PopulateContainer() error {
 		return fmt.Errorf("failed to get network usage: %w", err)
 	}
 
-	c.MountedVolumes, err = c.getContainerMountedVolumes()
+	c.Interfaces, err = c.getContainerNetworkInterfaces()
+	if err != nil {
+		return fmt.Errorf("failed to get network interfaces: %w", err)
+	}
+
+	c.Routes, err = c.getContainerRoutes()
+	if err != nil {
+		return fmt.Errorf("failed to get routes: %w", err)
+	}
+
+	c.Mounts, err = c.getContainerMounts()
+	if err != nil {
+		return fmt.Errorf("failed to get mounts: %w", err)
+	}
+	c.ReadOnlyRootfs = readOnlyRootfs(c.Mounts)
+	c.DiskUsage = c.getContainerDiskUsage()
+
+	c.Sockets, err = c.getContainerSockets()
 	if err != nil {
-		return fmt.Errorf("failed to get mounted volumes: %w", err)
+		return fmt.Errorf("failed to get sockets: %w", err)
 	}
 
-	c.ExposedPorts, err = c.getContainerExposedPorts()
+	c.Namespaces, err = c.getContainerNamespaces()
 	if err != nil {
-		return fmt.Errorf("failed to get exposed ports: %w", err)
+		return fmt.Errorf("failed to get namespaces: %w", err)
+	}
+
+	c.UIDMap, c.GIDMap, err = c.getContainerIDMappings()
+	if err != nil {
+		return fmt.Errorf("failed to get user namespace mappings: %w", err)
 	}
 
 	c.StartCommand, err = c.getContainerStartCommand()
@@ -231,6 +490,11 @@ func (c *Container) PopulateContainer() error {
 		return fmt.Errorf("failed to get security profiles: %w", err)
 	}
 
+	c.Security, err = c.getContainerSecurityStatus()
+	if err != nil {
+		return fmt.Errorf("failed to get security status: %w", err)
+	}
+
 	c.EnvVariables, err = c.getEnvironmentVariables()
 	if err != nil {
 		return fmt.Errorf("failed to get environment variables: %w", err)
@@ -241,15 +505,33 @@ func (c *Container) PopulateContainer() error {
 		return fmt.Errorf("failed to get resource usage: %w", err)
 	}
 
+	c.ResourceLimits, err = c.getContainerResourceLimits()
+	if err != nil {
+		return fmt.Errorf("failed to get resource limits: %w", err)
+	}
+
+	c.TopProcesses, err = c.getContainerProcesses()
+	if err != nil {
+		return fmt.Errorf("failed to get processes: %w", err)
+	}
+	c.markWritableExecutables()
+
 	return nil
 }
 
 // getContainerResourceUsage retrieves resource usage information
-// (CPU and memory) for the container.
+// (CPU, memory, swap, IO and pids) for the container. Usage is read from the
+// container's cgroup so it covers every process in the container, falling back
+// to the init process alone when the cgroup cannot be read.
 func (c *Container) getContainerResourceUsage() (ResourceUsage, error) {
-	cpuUsage, err := c.getContainerCPUUsage()
+	if usage, err := readCgroupUsage(c.PID); err == nil {
+		usage.SampledAt = time.Now()
+		return usage, nil
+	}
+
+	cpuTime, err := c.getContainerCPUTime()
 	if err != nil {
-		return ResourceUsage{}, fmt.Errorf("error getting CPU usage: %w", err)
+		return ResourceUsage{}, fmt.Errorf("error getting CPU time: %w", err)
 	}
 
 	memoryUsage, err := c.getContainerMemoryDetails()
@@ -257,14 +539,64 @@ func (c *Container) getContainerResourceUsage() (ResourceUsage, error) {
 		return ResourceUsage{}, fmt.Errorf("error getting memory usage: %w", err)
 	}
 
+	swapUsage, err := c.getContainerSwapUsage()
+	if err != nil {
+		return ResourceUsage{}, fmt.Errorf("error getting swap usage: %w", err)
+	}
+
 	return ResourceUsage{
-		CPUUsage:    cpuUsage,
+		CPUTime:     cpuTime,
 		MemoryUsage: memoryUsage,
+		SwapUsage:   swapUsage,
+		Pids:        1,
+		SampledAt:   time.Now(),
 	}, nil
 }
 
-// getContainerCPUUsage retrieves the CPU usage percentage for the container.
-func (c *Container) getContainerCPUUsage() (float64, error) {
+// getContainerResourceLimits retrieves the resource limits enforced on the container.
+// Limits are read from the container's cgroup, falling back to the linux.resources
+// section of the bundle's config.json when the cgroup cannot be read.
+func (c *Container) getContainerResourceLimits() (ResourceLimits, error) {
+	limits, err := readCgroupLimits(c.PID)
+	if err != nil {
+		if c.Spec == nil {
+			return ResourceLimits{}, fmt.Errorf("error reading cgroup limits: %w", err)
+		}
+		limits = c.Spec.resourceLimits()
+	}
+
+	// Kubernetes bandwidth limits are the only network limits a container can carry
+	if bandwidth, ok := c.Annotations["kubernetes.io/egress-bandwidth"]; ok {
+		if bitsPerSecond, err := parseBandwidth(bandwidth); err == nil {
+			// Kept in bytes per second, limits below 1 MB/s are common
+			limits.NetworkLimit = int(math.Ceil(bitsPerSecond / 8))
+		}
+	}
+
+	return limits, nil
+}
+
+// getContainerSpec reads the config.json of the container's bundle. Containers whose
+// runtime does not report a bundle, or whose bundle is gone, have no spec.
+func (c *Container) getContainerSpec() (*ociSpec, error) {
+	if c.Bundle == "" {
+		return nil, nil
+	}
+
+	spec, err := readBundleSpec(c.Bundle)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+// getContainerCPUTime retrieves the cumulative CPU time, in microseconds, of the
+// container's init process.
+func (c *Container) getContainerCPUTime() (uint64, error) {
 	p, err := process.NewProcess(int32(c.PID))
 	if err != nil {
 		return 0, err
@@ -275,9 +607,7 @@ func (c *Container) getContainerCPUUsage() (float64, error) {
 		return 0, err
 	}
 
-	cpuUsagePercentage := (cpuTimes.User + cpuTimes.System) * 100
-
-	return cpuUsagePercentage, nil
+	return uint64((cpuTimes.User + cpuTimes.System) * 1e6), nil
 }
 
 // getEnvironmentVariables retrieves the environment variables for the container.
@@ -295,22 +625,7 @@ func (c *Container) getEnvironmentVariables() ([]string, error) {
 	return envsSlice, nil
 }
 
-// getContainerExposedPorts retrieves the list of exposed ports for the container.
-func (c *Container) getContainerExposedPorts() ([]int, error) {
-	var ports []int
-	stats, err := net.ConnectionsPid("inet", int32(c.PID))
-	if err != nil {
-		return nil, err
-	}
-	for _, stat := range stats {
-		if stat.Status == "LISTEN" {
-			ports = append(ports, int(stat.Laddr.Port))
-		}
-	}
-	return ports, nil
-}
-
-// getContainerExposedPorts retrieves the list of exposed ports for the container.
+// getContainerStartCommand retrieves the command line the container was started with.
 func (c *Container) getContainerStartCommand() (string, error) {
 	p, err := process.NewProcess(int32(c.PID))
 	if err != nil {
@@ -326,7 +641,7 @@ func (c *Container) getContainerStartCommand() (string, error) {
 	return cmd, nil
 }
 
-// getContainerExposedPorts retrieves the list of exposed ports for the container.
+// getContainerSecurityProfiles retrieves the LSM profiles (AppArmor, SELinux) of the container.
 func (c *Container) getContainerSecurityProfiles() ([]string, error) {
 	path := fmt.Sprintf("/proc/%d/attr/current", c.PID)
 	content, err := os.ReadFile(path)
@@ -334,7 +649,8 @@ func (c *Container) getContainerSecurityProfiles() ([]string, error) {
 		return nil, err
 	}
 
-	profiles := strings.Split(string(content), ",")
+	// The attribute can be NUL-terminated
+	profiles := strings.Split(strings.TrimRight(string(content), "\x00\n"), ",")
 	for i := range profiles {
 		profiles[i] = strings.TrimSpace(profiles[i])
 	}
@@ -354,7 +670,7 @@ func (c *Container) getContainerSwapUsage() (int, error) {
 		return 0, err
 	}
 
-	return int(memoryInfo.Swap), nil
+	return int(memoryInfo.Swap / 1024), nil
 }
 
 // getContainerMemoryDetails retrieves detailed memory information (RSS and VMS)
@@ -371,136 +687,18 @@ func (c *Container) getContainerMemoryDetails() (map[string]int, error) {
 	}
 
 	memoryDetails := make(map[string]int)
-	memoryDetails["RSS"] = int(memoryInfo.RSS)
-	memoryDetails["VMS"] = int(memoryInfo.VMS)
+	memoryDetails["RSS"] = int(memoryInfo.RSS / 1024)
+	memoryDetails["VMS"] = int(memoryInfo.VMS / 1024)
 
 	return memoryDetails, nil
 }
 
-// getContainerNetworkInterfaces retrieves network interfaces and their IP addresses
-// associated with the container.
-func (c *Container) getContainerNetworkInterfaces() (map[string]string, error) {
-	info := make(map[string]string)
-	out, err := exec.Command("sudo", "nsenter", "-t", fmt.Sprint(c.PID), "-n", "ifconfig").Output()
-
-	if err != nil {
-		return nil, err
-	}
-
-	output := strings.Split(string(out), "\n")
-
-	for _, line := range output {
-		if strings.Contains(line, "inet ") {
-			fields := strings.Fields(line)
-			if len(fields) > 1 {
-				info[fields[0]] = fields[1]
-			}
-		}
-	}
-
-	return info, nil
-}
-
-// getOpenFiles retrieves a list of open files associated with the container.
-func (c *Container) getOpenFiles() ([]LsofOutput, error) {
-	cmd := exec.Command("sudo", "lsof", "-F", "-n", "-p", strconv.Itoa(c.PID))
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, err
-	}
-
-	lines := strings.Split(string(out), "\n")
-	var entries []LsofOutput
-	var entry LsofOutput
-	for _, line := range lines {
-		if len(line) > 1 {
-			switch line[0] {
-			case 'p':
-				if entry.PID != "" {
-					entries = append(entries, entry)
-					entry = LsofOutput{}
-				}
-				entry.PID = line[1:]
-			case 'c':
-				entry.Command = line[1:]
-			case 'u':
-				entry.User = line[1:]
-			case 'f':
-				entry.FD = line[1:]
-			case 't':
-				entry.Type = line[1:]
-			case 'D':
-				entry.Device = line[1:]
-			case 's':
-				entry.SizeOff = line[1:]
-			case 'i':
-				entry.Node = line[1:]
-			case 'n':
-				entry.Name = line[1:]
-			}
-		}
-	}
-	if entry.PID != "" {
-		entries = append(entries, entry)
-	}
-
-	return entries, nil
-}
-
-// getContainerNetworkUsage retrieves network usage statistics (received and transmitted bytes)
-// for the container.
-func (c *Container) getContainerNetworkUsage() (NetworkUsage, error) {
-	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/dev", c.PID))
-	if err != nil {
-		return NetworkUsage{}, err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	totalReceivedBytes := 0
-	totalTransmittedBytes := 0
-
-	for _, line := range lines {
-		fields := strings.Fields(line)
-
-		if len(fields) > 10 {
-			receivedBytes, err := strconv.Atoi(fields[1])
-			if err != nil {
-				continue
-			}
-
-			transmittedBytes, err := strconv.Atoi(fields[9])
-			if err != nil {
-				continue
-			}
-
-			totalReceivedBytes += receivedBytes
-			totalTransmittedBytes += transmittedBytes
-		}
-	}
-
-	return NetworkUsage{
-		ReceivedBytes:    totalReceivedBytes,
-		TransmittedBytes: totalTransmittedBytes,
-	}, nil
-}
-
-// getContainerMountedVolumes retrieves a list of mounted volumes within the container.
-func (c *Container) getContainerMountedVolumes() ([]string, error) {
-	volumes := []string{}
-
-	filePath := fmt.Sprintf("/proc/%d/mounts", c.PID)
-	fileContent, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, err
+// processIDs lists the PIDs of every process in the container's cgroup, falling back to
+// the init process alone when the cgroup cannot be read.
+func (c *Container) processIDs() []int {
+	pids, err := cgroupProcs(c.PID)
+	if err != nil || len(pids) == 0 {
+		return []int{c.PID}
 	}
-
-	lines := strings.Split(string(fileContent), "\n")
-	for _, line := range lines {
-		parts := strings.Split(line, " ")
-		if len(parts) > 2 {
-			volumes = append(volumes, parts[1])
-		}
-	}
-
-	return volumes, nil
+	return pids
 }
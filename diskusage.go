@@ -0,0 +1,179 @@
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// diskUsageInterval is how long a walked path's usage is reused before walking it again
+	diskUsageInterval = time.Minute
+	// diskUsagePause is the pause between two walks, so scanning does not hog the disk
+	diskUsagePause = 200 * time.Millisecond
+)
+
+type DiskUsage struct {
+	Target    string    `json:"target"`     // where the path is mounted in the container, "/" for the writable layer
+	Path      string    `json:"path"`       // path walked on the host
+	Bytes     uint64    `json:"bytes"`      // disk space used, like du
+	Inodes    uint64    `json:"inodes"`     // files, directories and links
+	ScannedAt time.Time `json:"scanned_at"` // zero until the first walk completes
+}
+
+var (
+	// Mutex for safe access to the disk usage cache
+	diskUsageMutex sync.Mutex
+	// Last walk result of each host path
+	diskUsageCache = make(map[string]DiskUsage)
+	// Host paths queued for a walk
+	diskUsagePending = make(map[string]bool)
+	// Paths waiting for the background walker
+	diskUsageQueue = make(chan string, 256)
+	// Starts the background walker on first use
+	diskUsageOnce sync.Once
+)
+
+// getContainerDiskUsage returns the disk usage of the container's writable layer and
+// volumes from the cache, and queues a background walk of the paths whose usage is
+// missing or stale.
+func (c *Container) getContainerDiskUsage() []DiskUsage {
+	diskUsageOnce.Do(func() {
+		go walkDiskUsage()
+	})
+
+	var usages []DiskUsage
+	for _, usage := range diskUsagePaths(c.Mounts) {
+		diskUsageMutex.Lock()
+		cached, ok := diskUsageCache[usage.Path]
+		if (!ok || time.Since(cached.ScannedAt) > diskUsageInterval) && !diskUsagePending[usage.Path] {
+			select {
+			case diskUsageQueue <- usage.Path:
+				diskUsagePending[usage.Path] = true
+			default:
+				// The queue is full, the path is queued again on the next refresh
+			}
+		}
+		diskUsageMutex.Unlock()
+
+		if ok {
+			cached.Target = usage.Target
+			usage = cached
+		}
+		usages = append(usages, usage)
+	}
+
+	return usages
+}
+
+// diskUsagePaths lists the host paths holding the container's data: the upper directory
+// of its overlay root filesystem and the host directories mounted as volumes.
+func diskUsagePaths(mounts []Mount) []DiskUsage {
+	var paths []DiskUsage
+	seen := make(map[string]bool)
+	for _, mount := range mounts {
+		path := mount.HostPath
+		if mount.Target == "/" {
+			// Only the upper directory of an overlay holds what the container wrote
+			path, _, _ = overlayDirs(mounts) // empty when it cannot be located
+		}
+
+		// Walking the host's root filesystem would scan the whole node
+		if path == "" || path == "/" || mount.Pseudo || seen[path] {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, DiskUsage{Target: mount.Target, Path: path})
+	}
+	return paths
+}
+
+// pruneDiskUsage forgets the usage of paths no container uses anymore.
+func pruneDiskUsage(containers []Container) {
+	inUse := make(map[string]bool)
+	for _, container := range containers {
+		for _, usage := range container.DiskUsage {
+			inUse[usage.Path] = true
+		}
+	}
+
+	diskUsageMutex.Lock()
+	for path := range diskUsageCache {
+		if !inUse[path] && !diskUsagePending[path] {
+			delete(diskUsageCache, path)
+		}
+	}
+	diskUsageMutex.Unlock()
+}
+
+// totalDiskUsage returns the bytes used by all of a container's paths.
+func totalDiskUsage(usages []DiskUsage) uint64 {
+	var total uint64
+	for _, usage := range usages {
+		total += usage.Bytes
+	}
+	return total
+}
+
+// walkDiskUsage walks the queued paths one at a time and caches their usage.
+func walkDiskUsage() {
+	for path := range diskUsageQueue {
+		usage := measureDiskUsage(path)
+
+		diskUsageMutex.Lock()
+		diskUsageCache[path] = usage
+		delete(diskUsagePending, path)
+		diskUsageMutex.Unlock()
+
+		time.Sleep(diskUsagePause)
+	}
+}
+
+// measureDiskUsage walks a path without crossing into other filesystems and adds up the
+// blocks and inodes it uses, counting hard-linked files once.
+func measureDiskUsage(path string) DiskUsage {
+	usage := DiskUsage{Path: path}
+
+	var rootDevice uint64
+	seen := make(map[[2]uint64]bool)
+	filepath.WalkDir(path, func(name string, entry fs.DirEntry, err error) error {
+		// Files can disappear while the container runs, skip what cannot be read
+		if err != nil {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return nil
+		}
+
+		if name == path {
+			rootDevice = uint64(stat.Dev)
+		} else if uint64(stat.Dev) != rootDevice {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if stat.Nlink > 1 && !entry.IsDir() {
+			key := [2]uint64{uint64(stat.Dev), uint64(stat.Ino)}
+			if seen[key] {
+				return nil
+			}
+			seen[key] = true
+		}
+
+		usage.Bytes += uint64(stat.Blocks) * 512
+		usage.Inodes++
+		return nil
+	})
+
+	usage.ScannedAt = time.Now()
+	return usage
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// opaqueXattrs mark an overlay directory that replaces, rather than merges with, the
+// directory of the same name in the lower layers
+var opaqueXattrs = []string{"trusted.overlay.opaque", "user.overlay.opaque"}
+
+// FileChange is a file the container added, modified or deleted compared to its image.
+type FileChange struct {
+	Path    string      `json:"path"` // path inside the container
+	Kind    string      `json:"kind"` // added, modified, deleted or replaced
+	Mode    fs.FileMode `json:"mode"`
+	Size    int64       `json:"size"`
+	ModTime time.Time   `json:"mod_time"`
+}
+
+// Executable reports whether the change left an executable regular file behind.
+func (f FileChange) Executable() bool {
+	return f.Kind != "deleted" && f.Mode.IsRegular() && f.Mode&0111 != 0
+}
+
+// getFilesystemDrift compares the upper directory of the container's overlay root
+// filesystem with the image layers below it.
+func (c *Container) getFilesystemDrift() ([]FileChange, error) {
+	upperDir, lowerDirs, err := overlayDirs(c.Mounts)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	err = filepath.WalkDir(upperDir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if name == upperDir {
+				return err
+			}
+			// Files can disappear while the container runs
+			return nil
+		}
+		if name == upperDir {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		relative, _ := filepath.Rel(upperDir, name)
+		change := FileChange{
+			Path:    "/" + relative,
+			Mode:    info.Mode(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}
+
+		inLower := existsInLowerDirs(lowerDirs, relative)
+		switch {
+		case isWhiteout(info):
+			change.Kind = "deleted"
+			change.Mode = 0
+			change.Size = 0
+		case entry.IsDir() && isOpaque(name):
+			change.Kind = "replaced"
+		case entry.IsDir() && inLower:
+			// Directories of the image are copied up to hold changed files, the files
+			// themselves are reported
+			return nil
+		case inLower:
+			change.Kind = "modified"
+		default:
+			change.Kind = "added"
+		}
+
+		changes = append(changes, change)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes, nil
+}
+
+// isWhiteout reports whether an upper directory entry is an overlay whiteout, a 0:0
+// character device that hides a deleted file of the lower layers.
+func isWhiteout(info fs.FileInfo) bool {
+	if info.Mode()&fs.ModeCharDevice == 0 {
+		return false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && stat.Rdev == 0
+}
+
+// isOpaque reports whether an upper directory hides the contents of the lower layers.
+func isOpaque(path string) bool {
+	value := make([]byte, 1)
+	for _, xattr := range opaqueXattrs {
+		if n, err := unix.Lgetxattr(path, xattr, value); err == nil && n == 1 && value[0] == 'y' {
+			return true
+		}
+	}
+	return false
+}
+
+// existsInLowerDirs reports whether a path exists in any of the image layers.
+func existsInLowerDirs(lowerDirs []string, relative string) bool {
+	for _, lowerDir := range lowerDirs {
+		if _, err := os.Lstat(filepath.Join(lowerDir, relative)); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// openFlags are the open(2) flags decoded from /proc/<pid>/fdinfo, besides the access mode
+var openFlags = []struct {
+	flag int
+	name string
+}{
+	{unix.O_APPEND, "O_APPEND"},
+	{unix.O_CREAT, "O_CREAT"},
+	{unix.O_EXCL, "O_EXCL"},
+	{unix.O_TRUNC, "O_TRUNC"},
+	{unix.O_NONBLOCK, "O_NONBLOCK"},
+	{unix.O_DSYNC, "O_DSYNC"},
+	{unix.O_DIRECT, "O_DIRECT"},
+	{unix.O_DIRECTORY, "O_DIRECTORY"},
+	{unix.O_NOFOLLOW, "O_NOFOLLOW"},
+	{unix.O_NOATIME, "O_NOATIME"},
+	{unix.O_CLOEXEC, "O_CLOEXEC"},
+	{unix.O_PATH, "O_PATH"},
+}
+
+// getOpenFiles retrieves the open files of every process in the container by reading
+// /proc/<pid>/fd and /proc/<pid>/fdinfo.
+func (c *Container) getOpenFiles() ([]LsofOutput, error) {
+	var entries []LsofOutput
+	users := make(map[string]string)
+
+	for _, pid := range c.processIDs() {
+		fds, err := os.ReadDir(fmt.Sprintf("/proc/%d/fd", pid))
+		if err != nil {
+			// Processes can exit while the container is being inspected
+			if os.IsNotExist(err) && pid != c.PID {
+				continue
+			}
+			return nil, err
+		}
+
+		comm, _ := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+		owner := processOwner(pid, users)
+
+		for _, fd := range fds {
+			entry, err := readOpenFile(pid, fd.Name())
+			if err != nil {
+				continue
+			}
+			entry.Command = strings.TrimSpace(string(comm))
+			entry.User = owner
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// readOpenFile describes a single file descriptor of a process.
+func readOpenFile(pid int, fd string) (LsofOutput, error) {
+	path := fmt.Sprintf("/proc/%d/fd/%s", pid, fd)
+	target, err := os.Readlink(path)
+	if err != nil {
+		return LsofOutput{}, err
+	}
+
+	entry := LsofOutput{
+		PID:  strconv.Itoa(pid),
+		FD:   fd,
+		Name: target,
+	}
+
+	// Classify the descriptor from its link target first, since sockets, pipes and
+	// anonymous inodes have no path to stat
+	switch {
+	case strings.HasPrefix(target, "socket:["):
+		entry.Type = "sock"
+	case strings.HasPrefix(target, "pipe:["):
+		entry.Type = "FIFO"
+	case target == "anon_inode:[eventfd]":
+		entry.Type = "eventfd"
+	case strings.HasPrefix(target, "anon_inode:"):
+		entry.Type = "a_inode"
+	}
+
+	// Stat through the descriptor so files in the container's mount namespace resolve
+	var stat syscall.Stat_t
+	if err := syscall.Stat(path, &stat); err == nil {
+		entry.Node = strconv.FormatUint(stat.Ino, 10)
+		switch stat.Mode & syscall.S_IFMT {
+		case syscall.S_IFREG:
+			entry.Type = "REG"
+			entry.SizeOff = strconv.FormatInt(stat.Size, 10)
+			entry.Device = fmt.Sprintf("%d,%d", unix.Major(stat.Dev), unix.Minor(stat.Dev))
+		case syscall.S_IFDIR:
+			entry.Type = "DIR"
+			entry.Device = fmt.Sprintf("%d,%d", unix.Major(stat.Dev), unix.Minor(stat.Dev))
+		case syscall.S_IFCHR:
+			entry.Type = "CHR"
+			entry.Device = fmt.Sprintf("%d,%d", unix.Major(stat.Rdev), unix.Minor(stat.Rdev))
+		case syscall.S_IFBLK:
+			entry.Type = "BLK"
+			entry.Device = fmt.Sprintf("%d,%d", unix.Major(stat.Rdev), unix.Minor(stat.Rdev))
+		case syscall.S_IFIFO:
+			entry.Type = "FIFO"
+		case syscall.S_IFSOCK:
+			entry.Type = "sock"
+		}
+	}
+	if entry.Type == "" {
+		entry.Type = "unknown"
+	}
+
+	// fdinfo holds the file position and the octal open flags, e.g. "pos:\t0\nflags:\t02100002"
+	fdinfo, err := os.Open(fmt.Sprintf("/proc/%d/fdinfo/%s", pid, fd))
+	if err != nil {
+		return entry, nil
+	}
+	defer fdinfo.Close()
+
+	scanner := bufio.NewScanner(fdinfo)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "pos":
+			entry.Pos, _ = strconv.ParseInt(value, 10, 64)
+		case "flags":
+			if flags, err := strconv.ParseInt(value, 8, 64); err == nil {
+				entry.FD += accessModeSuffix(int(flags))
+				entry.Flags = decodeOpenFlags(int(flags))
+			}
+		}
+	}
+
+	return entry, nil
+}
+
+// accessModeSuffix returns the lsof-style access mode suffix for a descriptor: r, w or u.
+func accessModeSuffix(flags int) string {
+	switch flags & unix.O_ACCMODE {
+	case unix.O_WRONLY:
+		return "w"
+	case unix.O_RDWR:
+		return "u"
+	default:
+		return "r"
+	}
+}
+
+// decodeOpenFlags names the open(2) flags set on a descriptor, e.g. O_RDWR|O_CLOEXEC.
+func decodeOpenFlags(flags int) string {
+	names := []string{"O_RDONLY"}
+	switch flags & unix.O_ACCMODE {
+	case unix.O_WRONLY:
+		names[0] = "O_WRONLY"
+	case unix.O_RDWR:
+		names[0] = "O_RDWR"
+	}
+
+	for _, f := range openFlags {
+		if flags&f.flag == f.flag {
+			names = append(names, f.name)
+		}
+	}
+
+	return strings.Join(names, "|")
+}
+
+// processOwner returns the name of the real user of a process, caching user lookups.
+func processOwner(pid int, users map[string]string) string {
+	status, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(status), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "Uid:" {
+			continue
+		}
+
+		uid := fields[1]
+		if name, ok := users[uid]; ok {
+			return name
+		}
+		name := uid
+		if u, err := user.LookupId(uid); err == nil {
+			name = u.Username
+		}
+		users[uid] = name
+		return name
+	}
+
+	return ""
+}
@@ -0,0 +1,82 @@
+package main
+
+import "time"
+
+// MetricSample is a snapshot of a container's resource usage at a point in time.
+type MetricSample struct {
+	Time         time.Time
+	CPUUsage     float64 // in percentage
+	MemoryUsage  int     // in kB
+	RxBytes      int     // cumulative
+	TxBytes      int     // cumulative
+	IOReadBytes  uint64  // cumulative
+	IOWriteBytes uint64  // cumulative
+	Pids         int
+}
+
+// metricHistory is a fixed-size ring buffer of samples for a single container.
+type metricHistory struct {
+	samples []MetricSample
+	next    int
+	full    bool
+}
+
+// histories holds the metric history of each container, keyed by ID and guarded by cacheMutex
+var histories = make(map[string]*metricHistory)
+
+// newMetricHistory creates a history holding at most size samples.
+func newMetricHistory(size int) *metricHistory {
+	if size < 1 {
+		size = 1
+	}
+	return &metricHistory{samples: make([]MetricSample, size)}
+}
+
+// add records a sample, overwriting the oldest one when the history is full.
+func (h *metricHistory) add(sample MetricSample) {
+	h.samples[h.next] = sample
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// all returns a copy of the recorded samples, oldest first.
+func (h *metricHistory) all() []MetricSample {
+	if !h.full {
+		return append([]MetricSample(nil), h.samples[:h.next]...)
+	}
+	return append(append([]MetricSample(nil), h.samples[h.next:]...), h.samples[:h.next]...)
+}
+
+// recordHistory adds the container's current usage to its history. The caller must hold cacheMutex.
+func recordHistory(container Container) {
+	history, ok := histories[container.ID]
+	if !ok {
+		history = newMetricHistory(config.HistoryLength)
+		histories[container.ID] = history
+	}
+
+	history.add(MetricSample{
+		Time:         container.ResourceUsage.SampledAt,
+		CPUUsage:     container.ResourceUsage.CPUUsage,
+		MemoryUsage:  container.ResourceUsage.MemoryInUse(),
+		RxBytes:      container.NetworkUsage.ReceivedBytes,
+		TxBytes:      container.NetworkUsage.TransmittedBytes,
+		IOReadBytes:  container.ResourceUsage.IOReadBytes,
+		IOWriteBytes: container.ResourceUsage.IOWriteBytes,
+		Pids:         container.ResourceUsage.Pids,
+	})
+}
+
+// GetHistory returns the samples recorded for a container, oldest first.
+func GetHistory(id string) []MetricSample {
+	cacheMutex.RLock()
+	defer cacheMutex.RUnlock()
+
+	history, ok := histories[id]
+	if !ok {
+		return nil
+	}
+	return history.all()
+}
@@ -1,16 +1,51 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-// Fetch container information and cache it before the application starts
-func init() {
+func main() {
+	// Load the configuration from flags and the optional config file
+	if err := loadConfig(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// Parse the user-defined policy rules
+	if err := compilePolicies(config.Rules); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// Compile the patterns environment variables are redacted by
+	if err := compileSecretPatterns(config.SecretPatterns); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	showPseudoMounts = config.ShowPseudoMounts
+
+	// Set up the container runtime backend
+	runtime, err := newRuntime(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	containerRuntime = runtime
+
+	// Fetch container information and cache it before the application starts
 	StartCacheRefresh()
-}
 
-func main() {
 	// Init the TUI
 	app := tview.NewApplication()
 
@@ -26,10 +61,23 @@ func main() {
 	// Set up detailed view of the container
 	detailsTextView := createDetailsTextview(app, table)
 
+	// Set up the process tree of the container
+	processTree := createProcessTree(app, table)
+
+	// Set up the list of policy violations and process drift alerts
+	alertsView := createAlertsView(app, table)
+
+	// Set up the tree of Kubernetes pods, shown in place of the table
+	podTree := createPodTree(app)
+
+	// Set up the pages, the filesystem drift view is shown on its own page
+	pages := tview.NewPages()
+	driftView := createDriftView(app, pages, table)
+
 	// Select the first container row, refresh the table view, and update container details
 	table.Select(1, 0)
-	refreshTable(table, detailsTextView)
-	updateDetails(table, detailsTextView)
+	refreshTable(table, detailsTextView, alertsView, podTree)
+	updateDetails(table, detailsTextView, processTree)
 
 	// Configure input capture logic for the TUI
 	// Hitting right arrow key moves to container details view
@@ -48,31 +96,110 @@ func main() {
 		case tcell.KeyUp:
 			if currentRow > 1 { // Starting from 1 to avoid table headers.
 				table.Select(currentRow-1, 0)
-				updateDetails(table, detailsTextView)
+				updateDetails(table, detailsTextView, processTree)
 			}
 			return nil // Prevent the default behavior of the table.
 		case tcell.KeyDown:
 			if currentRow < table.GetRowCount()-1 {
 				table.Select(currentRow+1, 0)
-				updateDetails(table, detailsTextView)
+				updateDetails(table, detailsTextView, processTree)
 			}
 			return nil // Prevent the default behavior of the table.
 		}
 		return event
 	})
 
+	// Selecting a container or pod in the tree selects its row in the table, so the
+	// details and the other shortcuts follow it
+	podTree.SetChangedFunc(func(node *tview.TreeNode) {
+		if ref, ok := node.GetReference().(podTreeRef); ok && ref.containerID != "" {
+			if selectContainerRow(table, ref.containerID) {
+				updateDetails(table, detailsTextView, processTree)
+			}
+		}
+	})
+	podTree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRight {
+			app.SetFocus(detailsTextView)
+			return nil
+		}
+		return event
+	})
+
+	// Show either the containers table or the pod tree on the left
+	listPages := tview.NewPages()
+	listPages.AddPage("table", table, true, true)
+	listPages.AddPage("pods", podTree, true, false)
+
+	// Stack the container details above the process tree
+	containerLayout := tview.NewFlex().SetDirection(tview.FlexRow)
+	containerLayout.AddItem(detailsTextView, 0, 2, false)
+	containerLayout.AddItem(processTree, 0, 1, false)
+
 	// Add main elements to the main layout
-	mainLayout.AddItem(table, 0, 1, true)
-	mainLayout.AddItem(detailsTextView, 0, 2, false)
+	mainLayout.AddItem(listPages, 0, 1, true)
+	mainLayout.AddItem(containerLayout, 0, 2, false)
 
 	// Add the main layout to the flex layout
 	flex.AddItem(mainLayout, 0, 10, true)
+	flex.AddItem(alertsView, 8, 0, false)
+
+	pages.AddPage("main", flex, true, true)
+	pages.AddPage("drift", driftView, true, false)
 
 	// Set up app-wide shortcuts
 	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Rune() {
 		case 'r': // Refresh table
-			refreshTable(table, detailsTextView)
+			refreshTable(table, detailsTextView, alertsView, podTree)
+		case 'c': // Cycle the connections filter
+			socketFilter = socketFilter.next()
+			updateDetails(table, detailsTextView, processTree)
+		case 'd': // Toggle sorting the table by disk usage
+			tableSort = tableSort.next()
+			refreshTable(table, detailsTextView, alertsView, podTree)
+		case 'f': // Show the filesystem drift of the selected container, or return from it
+			if name, _ := pages.GetFrontPage(); name == "drift" {
+				pages.SwitchToPage("main")
+				app.SetFocus(table)
+				break
+			}
+			if container, ok := selectedContainer(table); ok {
+				showDrift(app, container, driftView)
+				pages.SwitchToPage("drift")
+				app.SetFocus(driftView)
+			}
+		case 'm': // Show or hide pseudo filesystems among the mounts
+			showPseudoMounts = !showPseudoMounts
+			updateDetails(table, detailsTextView, processTree)
+		case 't': // Toggle between the containers table and the pod tree
+			if name, _ := listPages.GetFrontPage(); name == "pods" {
+				listPages.SwitchToPage("table")
+				app.SetFocus(table)
+				break
+			}
+			listPages.SwitchToPage("pods")
+			app.SetFocus(podTree)
+		case 'p': // Move to the process tree
+			app.SetFocus(processTree)
+		case 's': // Toggle the process tree between CPU and memory order
+			processSort = processSort.next()
+			updateDetails(table, detailsTextView, processTree)
+		case 'v': // Temporarily reveal the next masked environment variable
+			container, ok := selectedContainer(table)
+			if !ok {
+				break
+			}
+			if generation, ok := revealNextSecret(container); ok {
+				updateDetails(table, detailsTextView, processTree)
+				time.AfterFunc(secretRevealDuration, func() {
+					app.QueueUpdateDraw(func() {
+						if hideSecret(generation) {
+							updateDetails(table, detailsTextView, processTree)
+						}
+					})
+				})
+			}
 		case 'q': // Quit the application
 			app.Stop()
 		}
@@ -80,7 +207,7 @@ func main() {
 	})
 
 	// Start the application
-	if err := app.SetRoot(flex, true).EnableMouse(true).Run(); err != nil {
+	if err := app.SetRoot(pages, true).EnableMouse(true).Run(); err != nil {
 		panic(err)
 	}
 }
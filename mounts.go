@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// pseudoFilesystems are kernel filesystems mounted by the runtime that hold no container data
+var pseudoFilesystems = map[string]bool{
+	"proc":        true,
+	"sysfs":       true,
+	"cgroup":      true,
+	"cgroup2":     true,
+	"devpts":      true,
+	"mqueue":      true,
+	"devtmpfs":    true,
+	"securityfs":  true,
+	"debugfs":     true,
+	"tracefs":     true,
+	"pstore":      true,
+	"bpf":         true,
+	"configfs":    true,
+	"fusectl":     true,
+	"hugetlbfs":   true,
+	"binfmt_misc": true,
+	"autofs":      true,
+	"nsfs":        true,
+	"selinuxfs":   true,
+}
+
+type Mount struct {
+	ID               int      `json:"id"`
+	ParentID         int      `json:"parent_id"`
+	Device           string   `json:"device"` // major:minor of the mounted filesystem
+	Source           string   `json:"source"`
+	Root             string   `json:"root"` // path within the mounted filesystem
+	Target           string   `json:"target"`
+	FSType           string   `json:"fstype"`
+	Options          []string `json:"options"`
+	SuperOptions     []string `json:"super_options"`
+	Propagation      string   `json:"propagation"`
+	ReadOnly         bool     `json:"readonly"`
+	HostPath         string   `json:"host_path"` // where the mounted path lives on the host, for bind mounts
+	Pseudo           bool     `json:"pseudo"`
+	KubernetesVolume bool     `json:"kubernetes_volume"`
+}
+
+// getContainerMounts reads the mounts of the container from /proc/<pid>/mountinfo and
+// resolves where bind mounts come from on the host.
+func (c *Container) getContainerMounts() ([]Mount, error) {
+	mounts, err := readMountInfo(fmt.Sprintf("/proc/%d/mountinfo", c.PID))
+	if err != nil {
+		return nil, err
+	}
+
+	// Tachyon runs in the host's mount namespace, so its own mounts locate each
+	// filesystem on the host
+	hostMounts, err := readMountInfo("/proc/self/mountinfo")
+	if err != nil {
+		return nil, fmt.Errorf("error reading host mounts: %w", err)
+	}
+
+	for i := range mounts {
+		mounts[i].HostPath = hostPath(mounts[i], hostMounts)
+		// Secret, projected and memory-backed emptyDir volumes are tmpfs mounts whose
+		// root is "/", so only their host path tells them apart
+		mounts[i].KubernetesVolume = isKubernetesVolume(mounts[i].Root) || isKubernetesVolume(mounts[i].HostPath)
+	}
+
+	return mounts, nil
+}
+
+// isKubernetesVolume reports whether a path is in a pod volume directory of the kubelet.
+func isKubernetesVolume(path string) bool {
+	return strings.Contains(path, "/volumes/kubernetes.io~") || strings.Contains(path, "/volume-subpaths/")
+}
+
+// readMountInfo parses a mountinfo file, whose lines have the form
+// "36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue".
+func readMountInfo(path string) ([]Mount, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var mounts []Mount
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		// The optional fields end with a "-" separator
+		separator := -1
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				separator = i
+				break
+			}
+		}
+		if separator < 0 || len(fields) < separator+3 {
+			continue
+		}
+
+		id, _ := strconv.Atoi(fields[0])
+		parentID, _ := strconv.Atoi(fields[1])
+		mount := Mount{
+			ID:          id,
+			ParentID:    parentID,
+			Device:      fields[2],
+			Root:        unescapeMountPath(fields[3]),
+			Target:      unescapeMountPath(fields[4]),
+			Options:     strings.Split(fields[5], ","),
+			FSType:      fields[separator+1],
+			Source:      unescapeMountPath(fields[separator+2]),
+			Propagation: "private",
+		}
+		if len(fields) > separator+3 {
+			mount.SuperOptions = strings.Split(fields[separator+3], ",")
+		}
+
+		var propagation []string
+		for _, field := range fields[6:separator] {
+			if strings.HasPrefix(field, "shared:") || strings.HasPrefix(field, "master:") || field == "unbindable" {
+				propagation = append(propagation, field)
+			}
+		}
+		if len(propagation) > 0 {
+			mount.Propagation = strings.Join(propagation, " ")
+		}
+
+		for _, option := range mount.Options {
+			if option == "ro" {
+				mount.ReadOnly = true
+			}
+		}
+
+		mount.Pseudo = pseudoFilesystems[mount.FSType] ||
+			strings.HasPrefix(mount.Target, "/proc/") || strings.HasPrefix(mount.Target, "/sys/")
+
+		mounts = append(mounts, mount)
+	}
+
+	return mounts, scanner.Err()
+}
+
+// unescapeMountPath decodes the octal escapes (such as \040 for a space) the kernel uses
+// for whitespace and backslashes in mountinfo paths.
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, "\\") {
+		return path
+	}
+
+	var unescaped strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if value, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				unescaped.WriteByte(byte(value))
+				i += 3
+				continue
+			}
+		}
+		unescaped.WriteByte(path[i])
+	}
+	return unescaped.String()
+}
+
+// hostPath finds where a container mount's root lives on the host, by looking for a host
+// mount of the same filesystem whose root contains it. Filesystems the host does not
+// mount, such as the container's own tmpfs mounts, have no host path.
+func hostPath(mount Mount, hostMounts []Mount) string {
+	for _, hostMount := range hostMounts {
+		if hostMount.Device != mount.Device || hostMount.Pseudo {
+			continue
+		}
+
+		relative, err := filepath.Rel(hostMount.Root, mount.Root)
+		if err != nil || strings.HasPrefix(relative, "..") {
+			continue
+		}
+		return filepath.Join(hostMount.Target, relative)
+	}
+	return ""
+}
+
+// readOnlyRootfs reports whether the container's root filesystem is mounted read-only.
+func readOnlyRootfs(mounts []Mount) bool {
+	// Later mounts on / hide earlier ones, so the last one is in effect
+	readOnly := false
+	for _, mount := range mounts {
+		if mount.Target == "/" {
+			readOnly = mount.ReadOnly
+		}
+	}
+	return readOnly
+}
+
+// overlayDirs returns the upper directory and the lower directories of the container's
+// root filesystem, if it is an overlay. Docker's overlay2 switches to paths relative to
+// its own directory when the mount options get too long; those cannot be located from
+// the mount alone and are reported as an error rather than misread.
+func overlayDirs(mounts []Mount) (string, []string, error) {
+	var root *Mount
+	for i := range mounts {
+		if mounts[i].Target == "/" {
+			root = &mounts[i]
+		}
+	}
+	if root == nil || root.FSType != "overlay" {
+		return "", nil, errors.New("root filesystem is not an overlay")
+	}
+
+	var upperDir string
+	var lowerDirs []string
+	for _, option := range root.SuperOptions {
+		if dir, ok := strings.CutPrefix(option, "upperdir="); ok {
+			upperDir = dir
+		} else if dirs, ok := strings.CutPrefix(option, "lowerdir="); ok {
+			// Data-only layers are separated by "::", which leaves empty entries
+			for _, dir := range strings.Split(dirs, ":") {
+				if dir != "" {
+					lowerDirs = append(lowerDirs, dir)
+				}
+			}
+		}
+	}
+
+	if upperDir == "" {
+		return "", nil, errors.New("overlay root filesystem has no upper directory")
+	}
+	for _, dir := range append([]string{upperDir}, lowerDirs...) {
+		if !filepath.IsAbs(dir) {
+			return "", nil, fmt.Errorf("overlay directory %s is relative to the runtime's working directory", dir)
+		}
+	}
+
+	return upperDir, lowerDirs, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// namespaceTypes are the namespaces listed under /proc/<pid>/ns, in display order
+var namespaceTypes = []string{"mnt", "uts", "ipc", "pid", "net", "user", "cgroup", "time"}
+
+type Namespace struct {
+	Type           string   `json:"type"`
+	Inode          uint64   `json:"inode"`
+	SharedWithHost bool     `json:"shared_with_host"`
+	SharedWith     []string `json:"shared_with"` // IDs of the other containers in the namespace
+}
+
+type IDMapping struct {
+	ContainerID uint32 `json:"container_id"`
+	HostID      uint32 `json:"host_id"`
+	Size        uint32 `json:"size"`
+}
+
+// isIdentity reports whether the mappings map every ID to itself, which is the case
+// when the container runs in the host's user namespace.
+func isIdentity(mappings []IDMapping) bool {
+	return len(mappings) == 1 && mappings[0].ContainerID == 0 && mappings[0].HostID == 0 && mappings[0].Size == 4294967295
+}
+
+// overflowID is the ID the kernel reports for host IDs that a user namespace does not map
+const overflowID = 65534
+
+// containerID maps a host UID or GID to the ID it has inside a user namespace with the
+// given mappings. Without mappings the container is taken to share the host's IDs.
+func containerID(hostID int, mappings []IDMapping) int {
+	if len(mappings) == 0 {
+		return hostID
+	}
+	for _, mapping := range mappings {
+		if hostID >= int(mapping.HostID) && hostID-int(mapping.HostID) < int(mapping.Size) {
+			return int(mapping.ContainerID) + hostID - int(mapping.HostID)
+		}
+	}
+	return overflowID
+}
+
+// getContainerNamespaces reads the namespaces of the container's init process and
+// compares them with the namespaces of PID 1 to find the ones shared with the host.
+func (c *Container) getContainerNamespaces() ([]Namespace, error) {
+	var namespaces []Namespace
+	for _, nsType := range namespaceTypes {
+		inode, err := readNamespaceInode(c.PID, nsType)
+		if err != nil {
+			// Older kernels lack some namespace types, such as time
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		namespace := Namespace{Type: nsType, Inode: inode}
+		if hostInode, err := readNamespaceInode(1, nsType); err == nil {
+			namespace.SharedWithHost = hostInode == inode
+		}
+		namespaces = append(namespaces, namespace)
+	}
+
+	return namespaces, nil
+}
+
+// readNamespaceInode reads the inode of a process's namespace from the target of the
+// /proc/<pid>/ns/<type> link, which has the form "net:[4026531840]".
+func readNamespaceInode(pid int, nsType string) (uint64, error) {
+	target, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/%s", pid, nsType))
+	if err != nil {
+		return 0, err
+	}
+
+	start := strings.IndexByte(target, '[')
+	end := strings.LastIndexByte(target, ']')
+	if start < 0 || end < start {
+		return 0, fmt.Errorf("malformed namespace link %q", target)
+	}
+
+	return strconv.ParseUint(target[start+1:end], 10, 64)
+}
+
+// markSharedNamespaces records which of the other containers are in each of the
+// container's namespaces.
+func markSharedNamespaces(container *Container, others []Container) {
+	// Copies of the container handed out earlier share the slice, so it is not changed in place
+	container.Namespaces = append([]Namespace(nil), container.Namespaces...)
+	for i := range container.Namespaces {
+		namespace := &container.Namespaces[i]
+		namespace.SharedWith = nil
+		for _, other := range others {
+			if other.ID == container.ID {
+				continue
+			}
+			for _, otherNamespace := range other.Namespaces {
+				if otherNamespace.Type == namespace.Type && otherNamespace.Inode == namespace.Inode {
+					namespace.SharedWith = append(namespace.SharedWith, other.ID)
+				}
+			}
+		}
+	}
+}
+
+// getContainerIDMappings reads the UID and GID mappings of the container's user namespace.
+func (c *Container) getContainerIDMappings() ([]IDMapping, []IDMapping, error) {
+	uidMap, err := readIDMap(fmt.Sprintf("/proc/%d/uid_map", c.PID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gidMap, err := readIDMap(fmt.Sprintf("/proc/%d/gid_map", c.PID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return uidMap, gidMap, nil
+}
+
+// readIDMap parses a uid_map or gid_map file, whose lines hold the first ID inside the
+// namespace, the first ID outside it and the length of the range.
+func readIDMap(path string) ([]IDMapping, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var mappings []IDMapping
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+
+		var values [3]uint32
+		for i, field := range fields {
+			value, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("malformed mapping in %s: %w", path, err)
+			}
+			values[i] = uint32(value)
+		}
+		mappings = append(mappings, IDMapping{ContainerID: values[0], HostID: values[1], Size: values[2]})
+	}
+
+	return mappings, scanner.Err()
+}
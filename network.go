@@ -0,0 +1,528 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// getContainerNetworkUsage retrieves per-interface network statistics for the container
+// from /proc/<pid>/net/dev, which reflects the container's network namespace. Loopback
+// traffic is excluded from the totals unless IncludeLoopback is set.
+func (c *Container) getContainerNetworkUsage() (NetworkUsage, error) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/dev", c.PID))
+	if err != nil {
+		return NetworkUsage{}, err
+	}
+
+	usage := NetworkUsage{SampledAt: time.Now()}
+
+	// Each interface line looks like "  eth0: rx_bytes rx_packets rx_errs rx_drop ... tx_bytes ..."
+	for _, line := range strings.Split(string(content), "\n") {
+		name, counters, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(counters)
+		if len(fields) < 16 {
+			continue
+		}
+
+		values := make([]uint64, len(fields))
+		for i, field := range fields {
+			values[i], err = strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			continue
+		}
+
+		stats := InterfaceStats{
+			Name:      strings.TrimSpace(name),
+			RxBytes:   values[0],
+			RxPackets: values[1],
+			RxErrors:  values[2],
+			RxDropped: values[3],
+			TxBytes:   values[8],
+			TxPackets: values[9],
+			TxErrors:  values[10],
+			TxDropped: values[11],
+		}
+
+		if stats.Name == "lo" && !config.IncludeLoopback {
+			continue
+		}
+
+		usage.Interfaces = append(usage.Interfaces, stats)
+		usage.ReceivedBytes += int(stats.RxBytes)
+		usage.TransmittedBytes += int(stats.TxBytes)
+	}
+
+	return usage, nil
+}
+
+// updateNetworkRates computes the container's per-interface and total throughput from the
+// bytes transferred since the previous sample, and records the current sample. The caller
+// must hold cacheMutex.
+func updateNetworkRates(container *Container) {
+	usage := &container.NetworkUsage
+
+	previous, ok := networkSamples[container.ID]
+	networkSamples[container.ID] = *usage
+
+	// The first sample of a container has nothing to compare against
+	if !ok {
+		return
+	}
+
+	elapsed := usage.SampledAt.Sub(previous.SampledAt).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	previousInterfaces := make(map[string]InterfaceStats)
+	for _, stats := range previous.Interfaces {
+		previousInterfaces[stats.Name] = stats
+	}
+
+	usage.ReceiveRate = 0
+	usage.TransmitRate = 0
+	for i := range usage.Interfaces {
+		stats := &usage.Interfaces[i]
+		before, ok := previousInterfaces[stats.Name]
+		// Counters reset when an interface is recreated
+		if !ok || stats.RxBytes < before.RxBytes || stats.TxBytes < before.TxBytes {
+			continue
+		}
+		stats.ReceiveRate = float64(stats.RxBytes-before.RxBytes) / elapsed
+		stats.TransmitRate = float64(stats.TxBytes-before.TxBytes) / elapsed
+		usage.ReceiveRate += stats.ReceiveRate
+		usage.TransmitRate += stats.TransmitRate
+	}
+}
+
+// getContainerNetworkInterfaces retrieves the network interfaces of the container's
+// network namespace with their MTU, MAC address, flags and addresses. The interfaces
+// are read over netlink from a thread switched into the container's namespace, falling
+// back to the interface names and IPv6 addresses in /proc/<pid>/net when the namespace
+// cannot be entered.
+func (c *Container) getContainerNetworkInterfaces() ([]NetworkInterface, error) {
+	var interfaces []NetworkInterface
+	err := inNetworkNamespace(c.PID, func() error {
+		links, err := net.Interfaces()
+		if err != nil {
+			return err
+		}
+
+		for _, link := range links {
+			iface := NetworkInterface{
+				Name:  link.Name,
+				MTU:   link.MTU,
+				MAC:   link.HardwareAddr.String(),
+				Flags: link.Flags.String(),
+			}
+
+			addrs, err := link.Addrs()
+			if err != nil {
+				return err
+			}
+			for _, addr := range addrs {
+				iface.Addresses = append(iface.Addresses, addr.String())
+			}
+
+			interfaces = append(interfaces, iface)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return c.getProcNetworkInterfaces()
+	}
+
+	return interfaces, nil
+}
+
+// getProcNetworkInterfaces lists the container's interfaces from /proc/<pid>/net/dev with
+// the IPv6 addresses from /proc/<pid>/net/if_inet6, which can be read without entering the
+// network namespace.
+func (c *Container) getProcNetworkInterfaces() ([]NetworkInterface, error) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/dev", c.PID))
+	if err != nil {
+		return nil, err
+	}
+
+	var interfaces []NetworkInterface
+	index := make(map[string]int)
+	for _, line := range strings.Split(string(content), "\n") {
+		if name, _, ok := strings.Cut(line, ":"); ok {
+			index[strings.TrimSpace(name)] = len(interfaces)
+			interfaces = append(interfaces, NetworkInterface{Name: strings.TrimSpace(name)})
+		}
+	}
+
+	// if_inet6 lines look like "fe800000000000000000000000000001 02 40 20 80 eth0"
+	inet6, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/if_inet6", c.PID))
+	if err == nil {
+		for _, line := range strings.Split(string(inet6), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 6 {
+				continue
+			}
+			ip, err := hex.DecodeString(fields[0])
+			if err != nil || len(ip) != net.IPv6len {
+				continue
+			}
+			prefix, err := strconv.ParseUint(fields[2], 16, 8)
+			if err != nil {
+				continue
+			}
+			if i, ok := index[fields[5]]; ok {
+				interfaces[i].Addresses = append(interfaces[i].Addresses, fmt.Sprintf("%s/%d", net.IP(ip), prefix))
+			}
+		}
+	}
+
+	return interfaces, nil
+}
+
+// inNetworkNamespace runs fn on an OS thread switched into the network namespace of pid.
+func inNetworkNamespace(pid int, fn func() error) error {
+	errs := make(chan error, 1)
+
+	go func() {
+		// The namespace belongs to the thread, so keep the goroutine on it
+		runtime.LockOSThread()
+
+		hostNamespace, err := os.Open("/proc/thread-self/ns/net")
+		if err != nil {
+			runtime.UnlockOSThread()
+			errs <- err
+			return
+		}
+		defer hostNamespace.Close()
+
+		namespace, err := os.Open(fmt.Sprintf("/proc/%d/ns/net", pid))
+		if err != nil {
+			runtime.UnlockOSThread()
+			errs <- err
+			return
+		}
+		defer namespace.Close()
+
+		if err := unix.Setns(int(namespace.Fd()), unix.CLONE_NEWNET); err != nil {
+			runtime.UnlockOSThread()
+			errs <- fmt.Errorf("failed to enter network namespace of pid %d: %w", pid, err)
+			return
+		}
+
+		fnErr := fn()
+
+		// If the thread cannot switch back it stays locked, so it is discarded
+		// when the goroutine exits instead of being reused in the wrong namespace
+		if err := unix.Setns(int(hostNamespace.Fd()), unix.CLONE_NEWNET); err != nil {
+			errs <- fmt.Errorf("failed to restore network namespace: %w", err)
+			return
+		}
+
+		runtime.UnlockOSThread()
+		errs <- fnErr
+	}()
+
+	return <-errs
+}
+
+// getContainerRoutes retrieves the IPv4 and IPv6 routing tables of the container's network
+// namespace from /proc/<pid>/net/route and /proc/<pid>/net/ipv6_route.
+func (c *Container) getContainerRoutes() ([]Route, error) {
+	var routes []Route
+
+	// route has a header line, then "Iface Destination Gateway Flags RefCnt Use Metric Mask ..."
+	// with addresses as little-endian hex
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/route", c.PID))
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(content), "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			continue
+		}
+
+		destination, err := parseProcIPv4(fields[1])
+		if err != nil {
+			continue
+		}
+		gateway, err := parseProcIPv4(fields[2])
+		if err != nil {
+			continue
+		}
+		mask, err := parseProcIPv4(fields[7])
+		if err != nil {
+			continue
+		}
+		metric, _ := strconv.Atoi(fields[6])
+		ones, _ := net.IPMask(mask.To4()).Size()
+
+		route := Route{
+			Interface:   fields[0],
+			Destination: fmt.Sprintf("%s/%d", destination, ones),
+			Metric:      metric,
+		}
+		if !gateway.IsUnspecified() {
+			route.Gateway = gateway.String()
+		}
+		routes = append(routes, route)
+	}
+
+	// ipv6_route lines are "dest dest_len src src_len next_hop metric refcnt use flags iface"
+	// with addresses and numbers in hex
+	content, err = os.ReadFile(fmt.Sprintf("/proc/%d/net/ipv6_route", c.PID))
+	if err != nil {
+		// IPv6 can be disabled on the host
+		return routes, nil
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 10 || fields[9] == "lo" {
+			continue
+		}
+
+		destination, err := hex.DecodeString(fields[0])
+		if err != nil || len(destination) != net.IPv6len {
+			continue
+		}
+		prefix, err := strconv.ParseUint(fields[1], 16, 8)
+		if err != nil {
+			continue
+		}
+		gateway, err := hex.DecodeString(fields[4])
+		if err != nil || len(gateway) != net.IPv6len {
+			continue
+		}
+		metric, _ := strconv.ParseUint(fields[5], 16, 32)
+
+		route := Route{
+			Interface:   fields[9],
+			Destination: fmt.Sprintf("%s/%d", net.IP(destination), prefix),
+			Metric:      int(metric),
+		}
+		if !net.IP(gateway).IsUnspecified() {
+			route.Gateway = net.IP(gateway).String()
+		}
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+// parseProcIPv4 parses an IPv4 address written by the kernel as little-endian hex, e.g. 0100007F.
+func parseProcIPv4(value string) (net.IP, error) {
+	raw, err := hex.DecodeString(value)
+	if err != nil || len(raw) != net.IPv4len {
+		return nil, fmt.Errorf("invalid address %q", value)
+	}
+	return net.IPv4(raw[3], raw[2], raw[1], raw[0]), nil
+}
+
+// tcpStates maps the hex socket states in /proc/net/tcp to their names
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+// getContainerSockets retrieves every TCP, UDP and unix socket in the container's network
+// namespace from /proc/<pid>/net, along with the container process that owns each one.
+func (c *Container) getContainerSockets() ([]Socket, error) {
+	var sockets []Socket
+
+	for _, protocol := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		content, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/%s", c.PID, protocol))
+		if err != nil {
+			// IPv6 can be disabled on the host
+			if strings.HasSuffix(protocol, "6") {
+				continue
+			}
+			return nil, err
+		}
+		sockets = append(sockets, parseInetSockets(protocol, string(content))...)
+	}
+
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/unix", c.PID))
+	if err != nil {
+		return nil, err
+	}
+	sockets = append(sockets, parseUnixSockets(string(content))...)
+
+	// Match the socket inodes against the file descriptors of the container's processes
+	owners := make(map[uint64]int)
+	for _, pid := range c.processIDs() {
+		fds, err := os.ReadDir(fmt.Sprintf("/proc/%d/fd", pid))
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(fmt.Sprintf("/proc/%d/fd/%s", pid, fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]"), 10, 64)
+			if err == nil {
+				owners[inode] = pid
+			}
+		}
+	}
+
+	names := make(map[int]string)
+	for i := range sockets {
+		pid, ok := owners[sockets[i].Inode]
+		if !ok || sockets[i].Inode == 0 {
+			continue
+		}
+		if _, ok := names[pid]; !ok {
+			comm, _ := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+			names[pid] = strings.TrimSpace(string(comm))
+		}
+		sockets[i].PID = pid
+		sockets[i].Process = names[pid]
+	}
+
+	return sockets, nil
+}
+
+// parseInetSockets parses the lines of /proc/net/{tcp,tcp6,udp,udp6}, which look like
+// "sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...".
+func parseInetSockets(protocol, content string) []Socket {
+	var sockets []Socket
+	for _, line := range strings.Split(content, "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 10 {
+			continue
+		}
+
+		local, err := parseProcSocketAddress(fields[1])
+		if err != nil {
+			continue
+		}
+		remote, err := parseProcSocketAddress(fields[2])
+		if err != nil {
+			continue
+		}
+		inode, _ := strconv.ParseUint(fields[9], 10, 64)
+
+		state := tcpStates[fields[3]]
+		// UDP has no connection states, an unconnected socket is waiting for datagrams
+		if strings.HasPrefix(protocol, "udp") && state == "CLOSE" {
+			state = "UNCONN"
+		}
+
+		sockets = append(sockets, Socket{
+			Protocol:      protocol,
+			LocalAddress:  local,
+			RemoteAddress: remote,
+			State:         state,
+			Inode:         inode,
+		})
+	}
+	return sockets
+}
+
+// parseProcSocketAddress parses an address such as 0100007F:1F90, where the IP is written as
+// little-endian 32-bit words and the port as big-endian hex.
+func parseProcSocketAddress(value string) (string, error) {
+	ipHex, portHex, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", fmt.Errorf("invalid address %q", value)
+	}
+
+	raw, err := hex.DecodeString(ipHex)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", fmt.Errorf("invalid address %q", value)
+	}
+	// Reverse the bytes of each 32-bit word
+	ip := make(net.IP, len(raw))
+	for word := 0; word < len(raw); word += 4 {
+		for i := 0; i < 4; i++ {
+			ip[word+i] = raw[word+3-i]
+		}
+	}
+
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return "", fmt.Errorf("invalid port in %q: %w", value, err)
+	}
+
+	return net.JoinHostPort(ip.String(), strconv.FormatUint(port, 10)), nil
+}
+
+// fieldsRemainder returns what follows the first n space-separated fields of a line,
+// without the single space separating it from them.
+func fieldsRemainder(line string, n int) string {
+	rest := line
+	for i := 0; i < n; i++ {
+		rest = strings.TrimLeft(rest, " ")
+		end := strings.IndexByte(rest, ' ')
+		if end < 0 {
+			return ""
+		}
+		rest = rest[end:]
+	}
+	return strings.TrimPrefix(rest, " ")
+}
+
+// parseUnixSockets parses the lines of /proc/net/unix, which look like
+// "Num RefCount Protocol Flags Type St Inode Path".
+func parseUnixSockets(content string) []Socket {
+	const acceptConnections = 0x10000
+
+	var sockets []Socket
+	for _, line := range strings.Split(content, "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 7 {
+			continue
+		}
+
+		flags, _ := strconv.ParseUint(fields[3], 16, 32)
+		inode, _ := strconv.ParseUint(fields[6], 10, 64)
+
+		state := "UNCONN"
+		if flags&acceptConnections != 0 {
+			state = "LISTEN"
+		} else if fields[5] == "03" {
+			state = "ESTABLISHED"
+		}
+
+		// Abstract and unbound sockets have no path. Paths can contain spaces, so the
+		// path is the rest of the line after the seventh field
+		path := ""
+		if len(fields) > 7 {
+			path = fieldsRemainder(line, 7)
+		}
+
+		sockets = append(sockets, Socket{
+			Protocol:     "unix",
+			LocalAddress: path,
+			State:        state,
+			Inode:        inode,
+		})
+	}
+	return sockets
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ociSpec mirrors an OCI runtime spec, the config.json of a bundle.
+type ociSpec struct {
+	OciVersion  string            `json:"ociVersion"`
+	Process     *ociProcess       `json:"process"`
+	Root        *ociRoot          `json:"root"`
+	Hostname    string            `json:"hostname"`
+	Mounts      []ociMount        `json:"mounts"`
+	Hooks       *ociHooks         `json:"hooks"`
+	Annotations map[string]string `json:"annotations"`
+	Linux       struct {
+		Resources     *ociResources  `json:"resources"`
+		Namespaces    []ociNamespace `json:"namespaces"`
+		CgroupsPath   string         `json:"cgroupsPath"`
+		MaskedPaths   []string       `json:"maskedPaths"`
+		ReadonlyPaths []string       `json:"readonlyPaths"`
+	} `json:"linux"`
+}
+
+// ociProcess mirrors the process section of an OCI runtime spec.
+type ociProcess struct {
+	Terminal        bool             `json:"terminal"`
+	User            ociUser          `json:"user"`
+	Args            []string         `json:"args"`
+	Env             []string         `json:"env"`
+	Cwd             string           `json:"cwd"`
+	Capabilities    *ociCapabilities `json:"capabilities"`
+	Rlimits         []ociRlimit      `json:"rlimits"`
+	NoNewPrivileges bool             `json:"noNewPrivileges"`
+	ApparmorProfile string           `json:"apparmorProfile"`
+	SelinuxLabel    string           `json:"selinuxLabel"`
+}
+
+// ociUser is the user the container process runs as.
+type ociUser struct {
+	UID            uint32   `json:"uid"`
+	GID            uint32   `json:"gid"`
+	AdditionalGids []uint32 `json:"additionalGids"`
+	Username       string   `json:"username"`
+}
+
+// ociCapabilities are the capability sets the container process is started with.
+type ociCapabilities struct {
+	Bounding    []string `json:"bounding"`
+	Effective   []string `json:"effective"`
+	Inheritable []string `json:"inheritable"`
+	Permitted   []string `json:"permitted"`
+	Ambient     []string `json:"ambient"`
+}
+
+// ociRlimit is a resource limit set on the container process.
+type ociRlimit struct {
+	Type string `json:"type"`
+	Hard uint64 `json:"hard"`
+	Soft uint64 `json:"soft"`
+}
+
+// ociRoot is the container's root filesystem.
+type ociRoot struct {
+	Path     string `json:"path"`
+	Readonly bool   `json:"readonly"`
+}
+
+// ociMount is a mount set up in the container.
+type ociMount struct {
+	Destination string   `json:"destination"`
+	Type        string   `json:"type"`
+	Source      string   `json:"source"`
+	Options     []string `json:"options"`
+}
+
+// ociHooks are the hooks run at each stage of the container lifecycle.
+type ociHooks struct {
+	Prestart        []ociHook `json:"prestart"`
+	CreateRuntime   []ociHook `json:"createRuntime"`
+	CreateContainer []ociHook `json:"createContainer"`
+	StartContainer  []ociHook `json:"startContainer"`
+	Poststart       []ociHook `json:"poststart"`
+	Poststop        []ociHook `json:"poststop"`
+}
+
+// ociHook is a command run at a stage of the container lifecycle.
+type ociHook struct {
+	Path    string   `json:"path"`
+	Args    []string `json:"args"`
+	Env     []string `json:"env"`
+	Timeout *int     `json:"timeout"`
+}
+
+// ociNamespace is a namespace the container is created in or joins.
+type ociNamespace struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// ociResources mirrors the linux.resources section of an OCI runtime spec.
+type ociResources struct {
+	CPU *struct {
+		Shares uint64 `json:"shares"`
+		Quota  int64  `json:"quota"`
+		Period uint64 `json:"period"`
+		Cpus   string `json:"cpus"`
+		Mems   string `json:"mems"`
+	} `json:"cpu"`
+	Memory *struct {
+		Limit       int64 `json:"limit"`
+		Reservation int64 `json:"reservation"`
+		Swap        int64 `json:"swap"`
+	} `json:"memory"`
+	Pids *struct {
+		Limit int64 `json:"limit"`
+	} `json:"pids"`
+	BlockIO *struct {
+		Weight                 uint16              `json:"weight"`
+		ThrottleReadBpsDevice  []ociThrottleDevice `json:"throttleReadBpsDevice"`
+		ThrottleWriteBpsDevice []ociThrottleDevice `json:"throttleWriteBpsDevice"`
+	} `json:"blockIO"`
+}
+
+// ociHookStage is a lifecycle stage with the hooks run at it.
+type ociHookStage struct {
+	name  string
+	hooks []ociHook
+}
+
+// stages lists the hooks of each stage, in lifecycle order.
+func (h ociHooks) stages() []ociHookStage {
+	return []ociHookStage{
+		{"prestart", h.Prestart},
+		{"createRuntime", h.CreateRuntime},
+		{"createContainer", h.CreateContainer},
+		{"startContainer", h.StartContainer},
+		{"poststart", h.Poststart},
+		{"poststop", h.Poststop},
+	}
+}
+
+// ociThrottleDevice is a per-device IO rate limit.
+type ociThrottleDevice struct {
+	Major int64  `json:"major"`
+	Minor int64  `json:"minor"`
+	Rate  uint64 `json:"rate"`
+}
+
+// resourceLimits converts the linux.resources section of the spec to ResourceLimits.
+func (s ociSpec) resourceLimits() ResourceLimits {
+	var limits ResourceLimits
+	resources := s.Linux.Resources
+	if resources == nil {
+		return limits
+	}
+
+	if resources.CPU != nil && resources.CPU.Quota > 0 && resources.CPU.Period > 0 {
+		limits.CPULimit = float64(resources.CPU.Quota) / float64(resources.CPU.Period)
+	}
+
+	if resources.Memory != nil && resources.Memory.Limit > 0 {
+		limits.MemoryLimit = int(resources.Memory.Limit / 1024)
+	}
+
+	if resources.BlockIO != nil {
+		var rates []uint64
+		for _, device := range append(resources.BlockIO.ThrottleReadBpsDevice, resources.BlockIO.ThrottleWriteBpsDevice...) {
+			rates = append(rates, device.Rate)
+		}
+		limits.DiskIOLimit = diskIOLimit(rates)
+	}
+
+	return limits
+}
+
+// parseBandwidth parses a Kubernetes bandwidth quantity such as "10M" into bits per second.
+func parseBandwidth(quantity string) (float64, error) {
+	suffixes := []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+		{"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12},
+	}
+
+	multiplier := 1.0
+	for _, s := range suffixes {
+		if strings.HasSuffix(quantity, s.suffix) {
+			quantity = strings.TrimSuffix(quantity, s.suffix)
+			multiplier = s.multiplier
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth %q: %w", quantity, err)
+	}
+
+	return value * multiplier, nil
+}
+
+// readBundleSpec parses the config.json of an OCI bundle.
+func readBundleSpec(bundle string) (ociSpec, error) {
+	if bundle == "" {
+		return ociSpec{}, errors.New("container has no bundle")
+	}
+
+	path := filepath.Join(bundle, "config.json")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ociSpec{}, err
+	}
+
+	var spec ociSpec
+	if err := json.Unmarshal(content, &spec); err != nil {
+		return ociSpec{}, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"sort"
+)
+
+// Annotation and label keys set by the Kubernetes runtimes (containerd, CRI-O and
+// dockershim), in order of preference
+var (
+	podNamespaceKeys  = []string{"io.kubernetes.cri.sandbox-namespace", "io.kubernetes.pod.namespace"}
+	podNameKeys       = []string{"io.kubernetes.cri.sandbox-name", "io.kubernetes.pod.name"}
+	podSandboxKeys    = []string{"io.kubernetes.cri.sandbox-id", "io.kubernetes.cri-o.SandboxID", "io.kubernetes.sandbox.id"}
+	containerNameKeys = []string{"io.kubernetes.cri.container-name", "io.kubernetes.container.name", "io.kubernetes.cri-o.ContainerName"}
+	containerTypeKeys = []string{"io.kubernetes.cri.container-type", "io.kubernetes.cri-o.ContainerType", "io.kubernetes.docker.type"}
+)
+
+// podMetadata is what a container's annotations tell about the pod it belongs to.
+type podMetadata struct {
+	Namespace     string
+	Name          string
+	SandboxID     string
+	ContainerName string
+	Sandbox       bool // the container is the pod's sandbox (pause) container
+}
+
+// Pod groups the containers of a Kubernetes pod.
+type Pod struct {
+	Namespace   string
+	Name        string
+	SandboxID   string
+	Sandbox     *Container // the pause container, if the runtime reports one
+	Containers  []Container
+	CPUUsage    float64 // sum of the containers' CPU percentages
+	MemoryUsage int     // sum of the containers' memory usage, in kB
+}
+
+// firstAnnotation returns the value of the first of the keys set on the container.
+func firstAnnotation(container Container, keys []string) string {
+	for _, key := range keys {
+		if value := container.Annotations[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// getPodMetadata reads the pod a container belongs to from its annotations. Containers
+// not started by Kubernetes have none.
+func getPodMetadata(container Container) (podMetadata, bool) {
+	metadata := podMetadata{
+		Namespace:     firstAnnotation(container, podNamespaceKeys),
+		Name:          firstAnnotation(container, podNameKeys),
+		SandboxID:     firstAnnotation(container, podSandboxKeys),
+		ContainerName: firstAnnotation(container, containerNameKeys),
+	}
+	switch firstAnnotation(container, containerTypeKeys) {
+	case "sandbox", "podsandbox":
+		metadata.Sandbox = true
+		// A sandbox container is its own sandbox
+		if metadata.SandboxID == "" {
+			metadata.SandboxID = container.ID
+		}
+	}
+
+	if metadata.Name == "" && metadata.SandboxID == "" {
+		return podMetadata{}, false
+	}
+	return metadata, true
+}
+
+// groupPods groups containers by Kubernetes pod, ordered by namespace and name.
+// Containers that do not belong to a pod are returned separately.
+func groupPods(containers []Container) ([]Pod, []Container) {
+	pods := make(map[string]*Pod)
+	var standalone []Container
+
+	for _, container := range containers {
+		metadata, ok := getPodMetadata(container)
+		if !ok {
+			standalone = append(standalone, container)
+			continue
+		}
+
+		// Runtimes that do not report the sandbox ID are grouped by pod name
+		key := metadata.SandboxID
+		if key == "" {
+			key = metadata.Namespace + "/" + metadata.Name
+		}
+
+		pod, ok := pods[key]
+		if !ok {
+			pod = &Pod{SandboxID: metadata.SandboxID}
+			pods[key] = pod
+		}
+		if pod.Namespace == "" {
+			pod.Namespace = metadata.Namespace
+		}
+		if pod.Name == "" {
+			pod.Name = metadata.Name
+		}
+
+		pod.CPUUsage += container.ResourceUsage.CPUUsage
+		pod.MemoryUsage += container.ResourceUsage.MemoryInUse()
+		if metadata.Sandbox {
+			sandbox := container
+			pod.Sandbox = &sandbox
+		} else {
+			pod.Containers = append(pod.Containers, container)
+		}
+	}
+
+	grouped := make([]Pod, 0, len(pods))
+	for _, pod := range pods {
+		sort.Slice(pod.Containers, func(i, j int) bool {
+			return containerName(pod.Containers[i]) < containerName(pod.Containers[j])
+		})
+		grouped = append(grouped, *pod)
+	}
+	sort.Slice(grouped, func(i, j int) bool {
+		if grouped[i].Namespace != grouped[j].Namespace {
+			return grouped[i].Namespace < grouped[j].Namespace
+		}
+		if grouped[i].Name != grouped[j].Name {
+			return grouped[i].Name < grouped[j].Name
+		}
+		return grouped[i].SandboxID < grouped[j].SandboxID
+	})
+
+	return grouped, standalone
+}
+
+// containerName returns the Kubernetes name of a container, or its short ID.
+func containerName(container Container) string {
+	if name := firstAnnotation(container, containerNameKeys); name != "" {
+		return name
+	}
+	return shortContainerID(container.ID)
+}
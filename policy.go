@@ -0,0 +1,718 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PolicyRule is a user-defined rule whose expression describes a violation, for example
+// annotations["io.kubernetes.pod.namespace"] == "prod" && !readOnlyRootfs
+type PolicyRule struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+}
+
+// policy is a rule with its expression parsed
+type policy struct {
+	rule     PolicyRule
+	severity Severity
+	expr     policyExpr
+}
+
+// policies are the rules evaluated against every container on each refresh
+var policies []policy
+
+// policyExpr is a node of a parsed rule expression.
+type policyExpr interface {
+	eval(vars map[string]interface{}) (interface{}, error)
+}
+
+// compilePolicies parses the expressions of the configured rules.
+func compilePolicies(rules []PolicyRule) error {
+	policies = nil
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+
+		severity := SeverityMedium
+		if rule.Severity != "" {
+			var err error
+			if severity, err = parseSeverity(rule.Severity); err != nil {
+				return fmt.Errorf("rule %s: %w", rule.Name, err)
+			}
+		}
+
+		expr, err := parsePolicy(rule.Expression)
+		if err != nil {
+			return fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+
+		policies = append(policies, policy{rule: rule, severity: severity, expr: expr})
+	}
+	return nil
+}
+
+// parseSeverity converts a severity name to a Severity.
+func parseSeverity(name string) (Severity, error) {
+	for severity := SeverityLow; severity <= SeverityCritical; severity++ {
+		if strings.EqualFold(name, severity.String()) {
+			return severity, nil
+		}
+	}
+	return SeverityNone, fmt.Errorf("unknown severity %q", name)
+}
+
+// evaluatePolicies evaluates every rule against a container and returns the violations.
+// Rules that fail to evaluate are reported too, so mistakes in them do not go unnoticed.
+func evaluatePolicies(container Container) []Finding {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	vars := policyVariables(container)
+	var violations []Finding
+	for _, p := range policies {
+		result, err := p.expr.eval(vars)
+		if err != nil {
+			violations = append(violations, Finding{
+				Check:    p.rule.Name,
+				Severity: SeverityLow,
+				Message:  fmt.Sprintf("failed to evaluate: %v", err),
+			})
+			continue
+		}
+
+		violated, ok := result.(bool)
+		if !ok {
+			violations = append(violations, Finding{
+				Check:    p.rule.Name,
+				Severity: SeverityLow,
+				Message:  fmt.Sprintf("expression evaluates to %T, not a boolean", result),
+			})
+			continue
+		}
+
+		if violated {
+			message := p.rule.Message
+			if message == "" {
+				message = p.rule.Expression
+			}
+			violations = append(violations, Finding{Check: p.rule.Name, Severity: p.severity, Message: message})
+		}
+	}
+
+	return violations
+}
+
+// policyVariables exposes a container's data to rule expressions. Variables added here
+// must be listed in policyVariableNames too.
+func policyVariables(container Container) map[string]interface{} {
+	annotations := container.Annotations
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	env := make(map[string]string)
+	for _, variable := range container.EnvVariables {
+		if key, value, ok := strings.Cut(variable, "="); ok {
+			env[key] = value
+		}
+	}
+
+	var mounts, hostPaths []string
+	for _, mount := range container.Mounts {
+		mounts = append(mounts, mount.Target)
+		if mount.HostPath != "" && mount.Target != "/" {
+			hostPaths = append(hostPaths, mount.HostPath)
+		}
+	}
+
+	hostNamespaces := make(map[string]bool)
+	for _, namespace := range container.Namespaces {
+		hostNamespaces[namespace.Type] = namespace.SharedWithHost
+	}
+
+	return map[string]interface{}{
+		"id":             container.ID,
+		"pid":            float64(container.PID),
+		"status":         container.Status,
+		"namespace":      container.Namespace,
+		"owner":          container.Owner,
+		"image":          container.Image,
+		"root":           container.Root,
+		"bundle":         container.Bundle,
+		"annotations":    annotations,
+		"env":            env,
+		"mounts":         mounts,
+		"hostPaths":      hostPaths,
+		"readOnlyRootfs": container.ReadOnlyRootfs,
+		"runsAsRoot":     container.Security.RunsAsRoot(),
+		"uid":            float64(container.Security.UID),
+		"privileged":     isPrivileged(container),
+		"capabilities":   container.Security.Effective,
+		"seccomp":        container.Security.Seccomp,
+		"noNewPrivs":     container.Security.NoNewPrivs,
+		"hostNetwork":    hostNamespaces["net"],
+		"hostPID":        hostNamespaces["pid"],
+		"hostIPC":        hostNamespaces["ipc"],
+		"cpuUsage":       container.ResourceUsage.CPUUsage,
+		"memoryUsage":    float64(container.ResourceUsage.MemoryInUse()),
+		"cpuLimit":       container.ResourceLimits.CPULimit,
+		"memoryLimit":    float64(container.ResourceLimits.MemoryLimit),
+		"processes":      float64(container.ResourceUsage.Pids),
+		"diskUsage":      float64(totalDiskUsage(container.DiskUsage)),
+		"severity":       highestSeverity(container.Findings).String(),
+	}
+}
+
+// policyToken is a lexical token of a rule expression.
+type policyToken struct {
+	kind  string // "ident", "string", "number", "op" or "eof"
+	value string
+	pos   int
+}
+
+// policyOperators are the operators of the expression language, longest first
+var policyOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ","}
+
+// tokenizePolicy splits a rule expression into tokens. Positions are byte offsets.
+func tokenizePolicy(input string) ([]policyToken, error) {
+	var tokens []policyToken
+	for i := 0; i < len(input); {
+		c, size := utf8.DecodeRuneInString(input[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case c == '"':
+			// Find the closing quote, skipping escaped characters
+			end := i + 1
+			for end < len(input) && input[end] != '"' {
+				if input[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			value, err := strconv.Unquote(input[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d: %w", i, err)
+			}
+			tokens = append(tokens, policyToken{kind: "string", value: value, pos: i})
+			i = end + 1
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(input) && isASCIIDigit(input[i+1])):
+			// A leading minus belongs to the number, there is no subtraction
+			end := i + 1
+			for end < len(input) && (isASCIIDigit(input[end]) || input[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, policyToken{kind: "number", value: input[i:end], pos: i})
+			i = end
+		case unicode.IsLetter(c) || c == '_':
+			end := i + size
+			for end < len(input) {
+				next, nextSize := utf8.DecodeRuneInString(input[end:])
+				if !unicode.IsLetter(next) && !unicode.IsDigit(next) && next != '_' {
+					break
+				}
+				end += nextSize
+			}
+			tokens = append(tokens, policyToken{kind: "ident", value: input[i:end], pos: i})
+			i = end
+		default:
+			matched := false
+			for _, op := range policyOperators {
+				if strings.HasPrefix(input[i:], op) {
+					tokens = append(tokens, policyToken{kind: "op", value: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+			}
+		}
+	}
+	return append(tokens, policyToken{kind: "eof", pos: len(input)}), nil
+}
+
+// isASCIIDigit reports whether a byte is a decimal digit.
+func isASCIIDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// policyVariableNames are the variables rule expressions can refer to, one for each key
+// of policyVariables
+var policyVariableNames = map[string]bool{
+	"id": true, "pid": true, "status": true, "namespace": true, "owner": true, "image": true,
+	"root": true, "bundle": true, "annotations": true, "env": true, "mounts": true,
+	"hostPaths": true, "readOnlyRootfs": true, "runsAsRoot": true, "uid": true,
+	"privileged": true, "capabilities": true, "seccomp": true, "noNewPrivs": true,
+	"hostNetwork": true, "hostPID": true, "hostIPC": true, "cpuUsage": true,
+	"memoryUsage": true, "cpuLimit": true, "memoryLimit": true, "processes": true,
+	"diskUsage": true, "severity": true,
+}
+
+// policyParser is a recursive descent parser for rule expressions:
+//
+//	or         = and { "||" and }
+//	and        = comparison { "&&" comparison }
+//	comparison = unary [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" | "contains" | "matches" ) unary ]
+//	unary      = "!" unary | primary
+//	primary    = literal | ident [ "(" args ")" ] { "[" or "]" } | "(" or ")"
+//
+// Like in Go, "!" binds tighter than the comparisons: !a == b is (!a) == b.
+type policyParser struct {
+	tokens []policyToken
+	pos    int
+}
+
+// parsePolicy parses a rule expression.
+func parsePolicy(input string) (policyExpr, error) {
+	tokens, err := tokenizePolicy(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &policyParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != "eof" {
+		return nil, fmt.Errorf("unexpected %q at %d", token.value, token.pos)
+	}
+	return expr, nil
+}
+
+func (p *policyParser) peek() policyToken {
+	return p.tokens[p.pos]
+}
+
+func (p *policyParser) next() policyToken {
+	token := p.tokens[p.pos]
+	if token.kind != "eof" {
+		p.pos++
+	}
+	return token
+}
+
+// accept consumes the next token if it is the given operator or keyword.
+func (p *policyParser) accept(value string) bool {
+	token := p.peek()
+	if (token.kind == "op" || token.kind == "ident") && token.value == value {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes the given operator or fails.
+func (p *policyParser) expect(value string) error {
+	if !p.accept(value) {
+		token := p.peek()
+		return fmt.Errorf("expected %q at %d", value, token.pos)
+	}
+	return nil
+}
+
+func (p *policyParser) parseOr() (policyExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *policyParser) parseAnd() (policyExpr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *policyParser) parseUnary() (policyExpr, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *policyParser) parseComparison() (policyExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "contains", "matches"} {
+		if p.accept(op) {
+			right, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			compare := compareExpr{op: op, left: left, right: right}
+			// Literal patterns are compiled once, so a bad one is reported with the rule
+			if literal, ok := right.(literalExpr); ok && op == "matches" {
+				pattern, ok := literal.value.(string)
+				if !ok {
+					return nil, fmt.Errorf("matches needs a string pattern, not %T", literal.value)
+				}
+				if compare.pattern, err = regexp.Compile(pattern); err != nil {
+					return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+				}
+			}
+			return compare, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *policyParser) parsePrimary() (policyExpr, error) {
+	token := p.next()
+
+	var expr policyExpr
+	switch {
+	case token.kind == "string":
+		expr = literalExpr{value: token.value}
+	case token.kind == "number":
+		value, err := strconv.ParseFloat(token.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at %d", token.value, token.pos)
+		}
+		expr = literalExpr{value: value}
+	case token.kind == "ident" && (token.value == "true" || token.value == "false"):
+		expr = literalExpr{value: token.value == "true"}
+	case token.kind == "ident":
+		if p.accept("(") {
+			call := callExpr{name: token.value}
+			for !p.accept(")") {
+				if len(call.args) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				call.args = append(call.args, arg)
+			}
+			function, ok := policyFunctions[call.name]
+			if !ok {
+				return nil, fmt.Errorf("unknown function %q at %d", call.name, token.pos)
+			}
+			if len(call.args) != function.arity {
+				return nil, fmt.Errorf("%s takes %d argument(s), not %d, at %d", call.name, function.arity, len(call.args), token.pos)
+			}
+			expr = call
+		} else {
+			if !policyVariableNames[token.value] {
+				return nil, fmt.Errorf("unknown variable %q at %d", token.value, token.pos)
+			}
+			expr = variableExpr{name: token.value}
+		}
+	case token.kind == "op" && token.value == "(":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		expr = inner
+	case token.kind == "eof":
+		return nil, fmt.Errorf("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", token.value, token.pos)
+	}
+
+	for p.accept("[") {
+		index, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		expr = indexExpr{target: expr, index: index}
+	}
+
+	return expr, nil
+}
+
+type literalExpr struct {
+	value interface{}
+}
+
+func (e literalExpr) eval(vars map[string]interface{}) (interface{}, error) {
+	return e.value, nil
+}
+
+type variableExpr struct {
+	name string
+}
+
+func (e variableExpr) eval(vars map[string]interface{}) (interface{}, error) {
+	value, ok := vars[e.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %q", e.name)
+	}
+	return value, nil
+}
+
+type indexExpr struct {
+	target, index policyExpr
+}
+
+func (e indexExpr) eval(vars map[string]interface{}) (interface{}, error) {
+	target, err := e.target.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	index, err := e.index.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch target := target.(type) {
+	case map[string]string:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("map index must be a string, not %T", index)
+		}
+		// Missing keys read as empty strings, like Go maps
+		return target[key], nil
+	case []string:
+		i, ok := index.(float64)
+		if !ok {
+			return nil, fmt.Errorf("list index must be a number, not %T", index)
+		}
+		if int(i) < 0 || int(i) >= len(target) {
+			return "", nil
+		}
+		return target[int(i)], nil
+	default:
+		return nil, fmt.Errorf("cannot index %T", target)
+	}
+}
+
+type notExpr struct {
+	operand policyExpr
+}
+
+func (e notExpr) eval(vars map[string]interface{}) (interface{}, error) {
+	value, err := e.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %T", value)
+	}
+	return !b, nil
+}
+
+type logicalExpr struct {
+	op          string
+	left, right policyExpr
+}
+
+func (e logicalExpr) eval(vars map[string]interface{}) (interface{}, error) {
+	left, err := e.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	l, ok := left.(bool)
+	if !ok {
+		return nil, fmt.Errorf("operand of %s must be a boolean, not %T", e.op, left)
+	}
+
+	// Short-circuit like Go
+	if (e.op == "&&" && !l) || (e.op == "||" && l) {
+		return l, nil
+	}
+
+	right, err := e.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := right.(bool)
+	if !ok {
+		return nil, fmt.Errorf("operand of %s must be a boolean, not %T", e.op, right)
+	}
+	return r, nil
+}
+
+type compareExpr struct {
+	op          string
+	left, right policyExpr
+	pattern     *regexp.Regexp // the compiled pattern of matches, when it is a literal
+}
+
+func (e compareExpr) eval(vars map[string]interface{}) (interface{}, error) {
+	left, err := e.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "contains":
+		return containsValue(left, right)
+	case "matches":
+		s, ok1 := left.(string)
+		pattern, ok2 := right.(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("matches needs strings, not %T and %T", left, right)
+		}
+		if e.pattern != nil {
+			return e.pattern.MatchString(s), nil
+		}
+		return regexp.MatchString(pattern, s)
+	}
+
+	switch l := left.(type) {
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare string with %T", right)
+		}
+		return compareOrdered(e.op, strings.Compare(l, r))
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare number with %T", right)
+		}
+		switch {
+		case l < r:
+			return compareOrdered(e.op, -1)
+		case l > r:
+			return compareOrdered(e.op, 1)
+		default:
+			return compareOrdered(e.op, 0)
+		}
+	case bool:
+		r, ok := right.(bool)
+		if !ok || (e.op != "==" && e.op != "!=") {
+			return nil, fmt.Errorf("cannot compare boolean with %s %T", e.op, right)
+		}
+		return (l == r) == (e.op == "=="), nil
+	default:
+		return nil, fmt.Errorf("cannot compare %T", left)
+	}
+}
+
+// compareOrdered applies a comparison operator to the result of a three-way comparison.
+func compareOrdered(op string, cmp int) (bool, error) {
+	switch op {
+	case "==":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	}
+	return false, fmt.Errorf("unknown operator %s", op)
+}
+
+// containsValue reports whether a string contains a substring, a list contains an
+// element or a map contains a key.
+func containsValue(container, value interface{}) (bool, error) {
+	s, ok := value.(string)
+	if !ok {
+		return false, fmt.Errorf("contains needs a string operand, not %T", value)
+	}
+
+	switch container := container.(type) {
+	case string:
+		return strings.Contains(container, s), nil
+	case []string:
+		for _, element := range container {
+			if element == s {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]string:
+		_, ok := container[s]
+		return ok, nil
+	default:
+		return false, fmt.Errorf("%T cannot contain values", container)
+	}
+}
+
+// policyFunction is a function rule expressions can call, with its number of arguments
+// checked when the rule is parsed.
+type policyFunction struct {
+	arity int
+	call  func(args []interface{}) (interface{}, error)
+}
+
+// policyFunctions are the functions rule expressions can call
+var policyFunctions = map[string]policyFunction{
+	"len": {arity: 1, call: func(args []interface{}) (interface{}, error) {
+		switch arg := args[0].(type) {
+		case string:
+			return float64(len(arg)), nil
+		case []string:
+			return float64(len(arg)), nil
+		case map[string]string:
+			return float64(len(arg)), nil
+		default:
+			return nil, fmt.Errorf("len of %T", arg)
+		}
+	}},
+}
+
+type callExpr struct {
+	name string
+	args []policyExpr
+}
+
+func (e callExpr) eval(vars map[string]interface{}) (interface{}, error) {
+	args := make([]interface{}, len(e.args))
+	for i, arg := range e.args {
+		value, err := arg.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	return policyFunctions[e.name].call(args)
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenizePolicy(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string // kind:value of each token before eof
+		err   string
+	}{
+		{input: "", want: nil},
+		{input: "pid >= 10", want: []string{"ident:pid", "op:>=", "number:10"}},
+		{input: "cpuLimit<0.5", want: []string{"ident:cpuLimit", "op:<", "number:0.5"}},
+		{input: `!hostPID&&image!="nginx"`, want: []string{"op:!", "ident:hostPID", "op:&&", "ident:image", "op:!=", "string:nginx"}},
+		{input: `env["A_B"]`, want: []string{"ident:env", "op:[", "string:A_B", "op:]"}},
+		{input: `"say \"hi\""`, want: []string{`string:say "hi"`}},
+		{input: "len(mounts) > 2 || x_1", want: []string{"ident:len", "op:(", "ident:mounts", "op:)", "op:>", "number:2", "op:||", "ident:x_1"}},
+		{input: `image == "ünïcode 日本"`, want: []string{"ident:image", "op:==", "string:ünïcode 日本"}},
+		{input: `annotations["clé"]`, want: []string{"ident:annotations", "op:[", "string:clé", "op:]"}},
+		{input: "naïve_ß2", want: []string{"ident:naïve_ß2"}},
+		{input: "uid > -1", want: []string{"ident:uid", "op:>", "number:-1"}},
+		{input: "cpuUsage>=-0.5", want: []string{"ident:cpuUsage", "op:>=", "number:-0.5"}},
+		{input: "pid ≥ 1", err: "unexpected character '≥' at 4"},
+		{input: "pid > - 1", err: "unexpected character '-' at 6"},
+		{input: `"open`, err: "unterminated string"},
+		{input: "pid = 1", err: "unexpected character '='"},
+		{input: "a & b", err: "unexpected character '&'"},
+	}
+
+	for _, test := range tests {
+		tokens, err := tokenizePolicy(test.input)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("tokenizePolicy(%q) error = %v, want %q", test.input, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("tokenizePolicy(%q) error = %v", test.input, err)
+			continue
+		}
+
+		if last := tokens[len(tokens)-1]; last.kind != "eof" || last.pos != len(test.input) {
+			t.Errorf("tokenizePolicy(%q) ends with %+v, want eof at %d", test.input, last, len(test.input))
+		}
+		var got []string
+		for _, token := range tokens[:len(tokens)-1] {
+			got = append(got, token.kind+":"+token.value)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("tokenizePolicy(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestPolicyVariableNames(t *testing.T) {
+	vars := policyVariables(Container{})
+	for name := range vars {
+		if !policyVariableNames[name] {
+			t.Errorf("variable %q is missing from policyVariableNames", name)
+		}
+	}
+	for name := range policyVariableNames {
+		if _, ok := vars[name]; !ok {
+			t.Errorf("policyVariableNames lists %q, which policyVariables does not set", name)
+		}
+	}
+}
+
+func TestPolicyPrecedence(t *testing.T) {
+	container := Container{
+		PID:         42,
+		Status:      "running",
+		Image:       "nginx:1.25",
+		Annotations: map[string]string{"io.kubernetes.pod.namespace": "prod"},
+		Mounts:      []Mount{{Target: "/"}, {Target: "/data", HostPath: "/srv/data"}},
+	}
+
+	tests := []struct {
+		expression string
+		want       interface{}
+	}{
+		// && binds tighter than ||
+		{`true || false && false`, true},
+		{`(true || false) && false`, false},
+		{`false && true || true`, true},
+		// ! applies to the operand right after it
+		{`!false && false`, false},
+		{`!(false && false)`, true},
+		{`!!true`, true},
+		// ! binds tighter than comparisons, like in Go
+		{`!readOnlyRootfs == true`, true},
+		{`!(pid == 1)`, true},
+		// Negative numbers
+		{`pid > -1`, true},
+		{`-42 < -41.5`, true},
+		{`pid == -42`, false},
+		// Non-ASCII strings are compared whole
+		{`"日本語" contains "本"`, true},
+		{`len("日本")`, float64(6)},
+		// Comparisons bind tighter than logical operators
+		{`pid > 40 && pid < 50`, true},
+		{`pid == 42 || pid == 1 && status == "stopped"`, true},
+		{`!(pid == 42)`, false},
+		// Indexing and calls bind tighter than comparisons
+		{`annotations["io.kubernetes.pod.namespace"] == "prod"`, true},
+		{`annotations["missing"] == ""`, true},
+		{`mounts[1] == "/data"`, true},
+		{`mounts[5] == ""`, true},
+		{`len(mounts) >= 2 && len(hostPaths) == 1`, true},
+		{`hostPaths contains "/srv/data"`, true},
+		{`image contains "nginx" && !(image matches "^redis")`, true},
+		{`annotations contains "io.kubernetes.pod.namespace"`, true},
+		{`"abc" < "abd"`, true},
+		{`len(image)`, float64(10)},
+	}
+
+	vars := policyVariables(container)
+	for _, test := range tests {
+		expr, err := parsePolicy(test.expression)
+		if err != nil {
+			t.Errorf("parsePolicy(%q) error = %v", test.expression, err)
+			continue
+		}
+		got, err := expr.eval(vars)
+		if err != nil {
+			t.Errorf("eval(%q) error = %v", test.expression, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("eval(%q) = %v, want %v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestPolicyShortCircuit(t *testing.T) {
+	// The right operands would fail with a type error if they were evaluated
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`false && image > 1`, false},
+		{`true || image > 1`, true},
+		{`pid == 0 && !image`, false},
+		{`pid != 0 || len(pid) > 0`, true},
+	}
+
+	vars := policyVariables(Container{PID: 7, Image: "nginx"})
+	for _, test := range tests {
+		expr, err := parsePolicy(test.expression)
+		if err != nil {
+			t.Errorf("parsePolicy(%q) error = %v", test.expression, err)
+			continue
+		}
+		got, err := expr.eval(vars)
+		if err != nil {
+			t.Errorf("eval(%q) error = %v, want short-circuit", test.expression, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("eval(%q) = %v, want %v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestPolicyTypeErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        string
+	}{
+		{`image > 1`, "cannot compare string with float64"},
+		{`pid == "42"`, "cannot compare number with string"},
+		{`readOnlyRootfs < true`, "cannot compare boolean"},
+		{`!image`, "cannot negate string"},
+		// ! binds tighter than comparisons, so it applies to the left operand only
+		{`!image == "nginx["`, "cannot negate string"},
+		{`!pid == 42`, "cannot negate float64"},
+		{`!image contains "nginx"`, "cannot negate string"},
+		{`image && true`, "operand of && must be a boolean"},
+		{`true && pid`, "operand of && must be a boolean"},
+		{`mounts == "/"`, "cannot compare []string"},
+		{`pid contains "4"`, "float64 cannot contain values"},
+		{`image contains 4`, "contains needs a string operand"},
+		{`pid matches "4"`, "matches needs strings"},
+		{`annotations[1]`, "map index must be a string"},
+		{`mounts["a"]`, "list index must be a number"},
+		{`pid[0]`, "cannot index float64"},
+		{`len(pid)`, "len of float64"},
+		{`image matches image`, "error parsing regexp"},
+	}
+
+	vars := policyVariables(Container{PID: 42, Image: "nginx[", Mounts: []Mount{{Target: "/"}}})
+	for _, test := range tests {
+		expr, err := parsePolicy(test.expression)
+		if err != nil {
+			t.Errorf("parsePolicy(%q) error = %v", test.expression, err)
+			continue
+		}
+		if _, err := expr.eval(vars); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("eval(%q) error = %v, want %q", test.expression, err, test.err)
+		}
+	}
+}
+
+func TestCompilePolicies(t *testing.T) {
+	defer func() { policies = nil }()
+
+	tests := []struct {
+		rule PolicyRule
+		err  string
+	}{
+		{rule: PolicyRule{Name: "ok", Expression: `runsAsRoot && !readOnlyRootfs`, Severity: "high"}},
+		{rule: PolicyRule{Name: "typo", Expression: `foo == 1`}, err: `rule typo: unknown variable "foo" at 0`},
+		{rule: PolicyRule{Expression: `pid > 0 && bar`}, err: `rule rule-1: unknown variable "bar" at 11`},
+		{rule: PolicyRule{Name: "arity", Expression: `len() > 0`}, err: "len takes 1 argument(s), not 0"},
+		{rule: PolicyRule{Name: "arity", Expression: `len(image, id) > 0`}, err: "len takes 1 argument(s), not 2"},
+		{rule: PolicyRule{Name: "func", Expression: `size(image) > 0`}, err: `unknown function "size"`},
+		{rule: PolicyRule{Name: "regex", Expression: `image matches "nginx("`}, err: `invalid pattern "nginx("`},
+		{rule: PolicyRule{Name: "regex", Expression: `image matches 1`}, err: "matches needs a string pattern"},
+		{rule: PolicyRule{Name: "syntax", Expression: `pid >`}, err: "unexpected end of expression"},
+		{rule: PolicyRule{Name: "syntax", Expression: `(pid > 1`}, err: `expected ")"`},
+		{rule: PolicyRule{Name: "syntax", Expression: `pid 1`}, err: `unexpected "1" at 4`},
+		{rule: PolicyRule{Name: "severity", Expression: `true`, Severity: "urgent"}, err: `unknown severity "urgent"`},
+	}
+
+	for _, test := range tests {
+		err := compilePolicies([]PolicyRule{test.rule})
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("compilePolicies(%q) error = %v", test.rule.Expression, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("compilePolicies(%q) error = %v, want %q", test.rule.Expression, err, test.err)
+		}
+	}
+}
+
+func TestEvaluatePolicies(t *testing.T) {
+	defer func() { policies = nil }()
+
+	err := compilePolicies([]PolicyRule{
+		{Name: "root", Expression: `runsAsRoot`, Severity: "critical", Message: "runs as root"},
+		{Name: "image", Expression: `image matches "^nginx:"`},
+		{Name: "number", Expression: `pid`},
+		{Name: "dynamic", Expression: `image matches env["PATTERN"]`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	container := Container{PID: 3, Image: "nginx:1.25", EnvVariables: []string{"PATTERN=("}}
+	container.Security.UID = 1000
+
+	want := []Finding{
+		{Check: "image", Severity: SeverityMedium, Message: `image matches "^nginx:"`},
+		{Check: "number", Severity: SeverityLow, Message: "expression evaluates to float64, not a boolean"},
+	}
+	got := evaluatePolicies(container)
+	if len(got) != 3 || !reflect.DeepEqual(got[:2], want) {
+		t.Fatalf("evaluatePolicies() = %+v, want %+v and a dynamic pattern error", got, want)
+	}
+	if got[2].Check != "dynamic" || !strings.HasPrefix(got[2].Message, "failed to evaluate: error parsing regexp") {
+		t.Errorf("evaluatePolicies() dynamic = %+v, want a regexp error", got[2])
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/mem"
+)
+
+// clockTicks is USER_HZ, the unit of the CPU times in /proc/<pid>/stat, which is 100 on
+// every architecture Linux supports
+const clockTicks = 100
+
+// getContainerProcesses retrieves every process in the container's cgroup.
+func (c *Container) getContainerProcesses() ([]ProcessInfo, error) {
+	memory, err := mem.VirtualMemory()
+	if err != nil {
+		return nil, fmt.Errorf("error reading total memory: %w", err)
+	}
+
+	var processes []ProcessInfo
+	users := make(map[string]string)
+	for _, pid := range c.processIDs() {
+		info, err := readProcessInfo(pid)
+		if err != nil {
+			// Processes can exit while the container is being inspected
+			continue
+		}
+		info.User = processOwner(pid, users)
+		info.MEM = float64(info.RSS) * 1024 / float64(memory.Total) * 100
+		processes = append(processes, info)
+	}
+
+	if len(processes) == 0 {
+		return nil, fmt.Errorf("no processes found for pid %d", c.PID)
+	}
+
+	return processes, nil
+}
+
+// procStat holds the fields of /proc/<pid>/stat used by Tachyon.
+type procStat struct {
+	Comm      string
+	State     string
+	PPID      int
+	UTime     uint64 // in clock ticks
+	STime     uint64 // in clock ticks
+	Threads   int
+	StartTime uint64 // in clock ticks since boot
+	RSSPages  int
+}
+
+// readProcStat parses /proc/<pid>/stat.
+func readProcStat(pid int) (procStat, error) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return procStat{}, err
+	}
+
+	// The command name can contain spaces, so split after its closing parenthesis
+	stat := string(content)
+	start := strings.IndexByte(stat, '(')
+	end := strings.LastIndexByte(stat, ')')
+	if start < 0 || end < start {
+		return procStat{}, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+
+	// Fields after the command start at field 3 (state)
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 22 {
+		return procStat{}, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+
+	parsed := procStat{
+		Comm:  stat[start+1 : end],
+		State: fields[0],
+	}
+	parsed.PPID, _ = strconv.Atoi(fields[1])
+	parsed.UTime, _ = strconv.ParseUint(fields[11], 10, 64)
+	parsed.STime, _ = strconv.ParseUint(fields[12], 10, 64)
+	parsed.Threads, _ = strconv.Atoi(fields[17])
+	parsed.RSSPages, _ = strconv.Atoi(fields[21])
+	parsed.StartTime, err = strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return procStat{}, fmt.Errorf("malformed stat for pid %d: %w", pid, err)
+	}
+
+	return parsed, nil
+}
+
+// readProcessInfo reads a process's details from /proc/<pid>/stat, status and cmdline.
+func readProcessInfo(pid int) (ProcessInfo, error) {
+	stat, err := readProcStat(pid)
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+
+	info := ProcessInfo{
+		PID:       pid,
+		PPID:      stat.PPID,
+		NSPID:     pid,
+		State:     stat.State,
+		Threads:   stat.Threads,
+		RSS:       stat.RSSPages * os.Getpagesize() / 1024,
+		CPUTime:   (stat.UTime + stat.STime) * 1e6 / clockTicks,
+		StartTime: stat.StartTime,
+		SampledAt: time.Now(),
+		CMD:       stat.Comm,
+	}
+
+	// NSpid lists the PID in each nested PID namespace, innermost last
+	status, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err == nil {
+		for _, line := range strings.Split(string(status), "\n") {
+			if nspids, ok := strings.CutPrefix(line, "NSpid:"); ok {
+				ids := strings.Fields(nspids)
+				if len(ids) > 0 {
+					info.NSPID, _ = strconv.Atoi(ids[len(ids)-1])
+				}
+				break
+			}
+		}
+	}
+
+	// Kernel threads have no executable, and deleted executables get a " (deleted)" suffix
+	if exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid)); err == nil {
+		info.Exe = exe
+	}
+	// The link resolves to the executable's inode even when it was deleted
+	var exeStat syscall.Stat_t
+	if err := syscall.Stat(fmt.Sprintf("/proc/%d/exe", pid), &exeStat); err == nil {
+		info.ExeChanged = time.Unix(exeStat.Ctim.Unix())
+	}
+
+	// Kernel threads and zombies have an empty command line
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err == nil && len(cmdline) > 0 {
+		// Arguments are NUL separated and can contain newlines of their own
+		info.CMD = strings.Join(strings.Fields(strings.ReplaceAll(string(cmdline), "\x00", " ")), " ")
+	}
+
+	return info, nil
+}
+
+// updateProcessCPU computes the CPU percentage of each of the container's processes from
+// the CPU time it consumed since the previous sample, and records the current samples.
+// The caller must hold cacheMutex.
+func updateProcessCPU(container *Container) {
+	previous := make(map[int]ProcessInfo)
+	for _, process := range processSamples[container.ID] {
+		previous[process.PID] = process
+	}
+	processSamples[container.ID] = container.TopProcesses
+
+	for i := range container.TopProcesses {
+		process := &container.TopProcesses[i]
+		before, ok := previous[process.PID]
+		// A PID reused by a new process has a different start time
+		if !ok || before.StartTime != process.StartTime || process.CPUTime < before.CPUTime {
+			continue
+		}
+
+		elapsed := process.SampledAt.Sub(before.SampledAt).Microseconds()
+		if elapsed <= 0 {
+			continue
+		}
+		process.CPU = float64(process.CPUTime-before.CPUTime) / float64(elapsed) * 100
+		if !config.PerCoreCPU {
+			process.CPU /= float64(runtime.NumCPU())
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// What each container looked like when Tachyon first observed it
+	executableBaselines = make(map[string]executableBaseline)
+	// Process drift alerts raised for each container, oldest first
+	processAlerts = make(map[string][]ProcessAlert)
+)
+
+// executableBaseline records when a container was first observed and which executables
+// it was running then.
+type executableBaseline struct {
+	ObservedAt time.Time
+	Running    map[string]bool
+}
+
+// present reports whether a process's executable already existed when the container was
+// first observed: it was running then, or its file has not changed since.
+func (b executableBaseline) present(process ProcessInfo) bool {
+	if b.Running[process.Exe] {
+		return true
+	}
+	return !process.ExeChanged.IsZero() && !process.ExeChanged.After(b.ObservedAt)
+}
+
+// ProcessAlert is a process whose executable drifted from the container's baseline.
+type ProcessAlert struct {
+	PID        int       `json:"pid"`
+	Exe        string    `json:"exe"`
+	CMD        string    `json:"cmd"`
+	Reasons    []string  `json:"reasons"`
+	Severity   Severity  `json:"severity"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// markWritableExecutables flags the processes whose executable was written to the
+// container's writable layer rather than shipped in its image.
+func (c *Container) markWritableExecutables() {
+	upperDir, _, err := overlayDirs(c.Mounts)
+	if err != nil {
+		return
+	}
+
+	for i := range c.TopProcesses {
+		exe := strings.TrimSuffix(c.TopProcesses[i].Exe, " (deleted)")
+		if !filepath.IsAbs(exe) {
+			continue
+		}
+		info, err := os.Lstat(filepath.Join(upperDir, exe))
+		c.TopProcesses[i].WritableExe = err == nil && !isWhiteout(info)
+	}
+}
+
+// updateProcessDrift checks the executables of the container's processes against the
+// container as it was first observed, and raises an alert the first time each drifted
+// executable is seen. The caller must hold cacheMutex.
+func updateProcessDrift(container *Container) {
+	baseline, ok := executableBaselines[container.ID]
+	if !ok {
+		baseline = executableBaseline{ObservedAt: time.Now(), Running: make(map[string]bool)}
+		for _, process := range container.TopProcesses {
+			if process.Exe != "" {
+				baseline.Running[process.Exe] = true
+			}
+		}
+		executableBaselines[container.ID] = baseline
+	}
+
+	alerted := make(map[string]bool)
+	for _, alert := range processAlerts[container.ID] {
+		alerted[alert.Exe] = true
+	}
+
+	for _, process := range container.TopProcesses {
+		if process.Exe == "" || alerted[process.Exe] {
+			continue
+		}
+
+		var reasons []string
+		severity := SeverityMedium
+		if !baseline.present(process) {
+			reasons = append(reasons, "executable created or modified after first observation")
+		}
+		if process.WritableExe {
+			reasons = append(reasons, "executable in the writable layer")
+			severity = SeverityHigh
+		}
+		if strings.HasSuffix(process.Exe, " (deleted)") || strings.HasPrefix(process.Exe, "/memfd:") {
+			reasons = append(reasons, "executable deleted or memory-backed")
+			severity = SeverityCritical
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		alerted[process.Exe] = true
+		processAlerts[container.ID] = append(processAlerts[container.ID], ProcessAlert{
+			PID:        process.PID,
+			Exe:        process.Exe,
+			CMD:        process.CMD,
+			Reasons:    reasons,
+			Severity:   severity,
+			DetectedAt: process.SampledAt,
+		})
+	}
+
+	container.ProcessAlerts = processAlerts[container.ID]
+}
+
+// String describes the alert in one line.
+func (a ProcessAlert) String() string {
+	return fmt.Sprintf("%s (pid %d): %s", a.Exe, a.PID, strings.Join(a.Reasons, ", "))
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUpdateProcessDrift(t *testing.T) {
+	t.Cleanup(func() {
+		delete(executableBaselines, "drift")
+		delete(processAlerts, "drift")
+	})
+
+	old := time.Now().Add(-time.Hour)
+	container := Container{ID: "drift", TopProcesses: []ProcessInfo{
+		{PID: 1, Exe: "/usr/sbin/nginx", ExeChanged: old},
+		{PID: 2, Exe: "/tmp/dropped", ExeChanged: time.Now(), WritableExe: true},
+	}}
+	updateProcessDrift(&container)
+
+	// A process running at first observation only alerts for its writable layer binary
+	if len(container.ProcessAlerts) != 1 || !reflect.DeepEqual(container.ProcessAlerts[0].Reasons, []string{"executable in the writable layer"}) {
+		t.Fatalf("first observation alerts = %+v, want the writable layer binary only", container.ProcessAlerts)
+	}
+
+	later := executableBaselines["drift"].ObservedAt.Add(time.Minute)
+	container.TopProcesses = []ProcessInfo{
+		// Shipped in the image, started after the first observation
+		{PID: 3, Exe: "/bin/sh", ExeChanged: old},
+		// Written after the first observation
+		{PID: 4, Exe: "/usr/bin/curl", ExeChanged: later},
+		{PID: 5, Exe: "/tmp/miner (deleted)", ExeChanged: later, WritableExe: true},
+		// The change time of the executable could not be read
+		{PID: 6, Exe: "/memfd:x (deleted)"},
+	}
+	updateProcessDrift(&container)
+
+	want := map[string][]string{
+		"/tmp/dropped":  {"executable in the writable layer"},
+		"/usr/bin/curl": {"executable created or modified after first observation"},
+		"/tmp/miner (deleted)": {
+			"executable created or modified after first observation",
+			"executable in the writable layer",
+			"executable deleted or memory-backed",
+		},
+		"/memfd:x (deleted)": {
+			"executable created or modified after first observation",
+			"executable deleted or memory-backed",
+		},
+	}
+	got := make(map[string][]string)
+	for _, alert := range container.ProcessAlerts {
+		got[alert.Exe] = alert.Reasons
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("alerts = %v, want %v", got, want)
+	}
+	if severity := container.ProcessAlerts[len(container.ProcessAlerts)-1].Severity; severity != SeverityCritical {
+		t.Errorf("severity of the memory-backed executable = %v, want %v", severity, SeverityCritical)
+	}
+}
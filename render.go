@@ -44,8 +44,110 @@ func createDetailsTextview(app *tview.Application, table *tview.Table) *tview.Te
 	return textView
 }
 
-// createDetailsTextview creates and configures a text view widget for displaying container details.
-func refreshTable(table *tview.Table, detailsTextView *tview.TextView) {
+// createProcessTree creates and configures a tree widget for displaying the container's processes.
+// Selecting a process expands or collapses its children.
+func createProcessTree(app *tview.Application, table *tview.Table) *tview.TreeView {
+	tree := tview.NewTreeView()
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+		if pid, ok := node.GetReference().(int); ok {
+			collapsedProcesses[pid] = !node.IsExpanded()
+		}
+	})
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyLeft:
+			app.SetFocus(table)
+		}
+		return event
+	})
+
+	tree.SetBackgroundColor(tcell.ColorBlack).SetBorder(true).SetTitle(" Processes ").SetBorderPadding(0, 0, 1, 1)
+
+	return tree
+}
+
+// podTreeRef is the reference of a pod tree node. Nodes of containers, and of pods with
+// a sandbox container, carry the container's ID.
+type podTreeRef struct {
+	key         string
+	containerID string
+}
+
+// collapsedPodNodes remembers which namespaces and pods were collapsed across refreshes
+var collapsedPodNodes = make(map[string]bool)
+
+// createPodTree creates and configures a tree widget grouping containers by Kubernetes
+// namespace and pod. Selecting a namespace or pod expands or collapses it.
+func createPodTree(app *tview.Application) *tview.TreeView {
+	tree := tview.NewTreeView()
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		if len(node.GetChildren()) == 0 {
+			return
+		}
+		node.SetExpanded(!node.IsExpanded())
+		if ref, ok := node.GetReference().(podTreeRef); ok {
+			collapsedPodNodes[ref.key] = !node.IsExpanded()
+		}
+	})
+
+	tree.SetBackgroundColor(tcell.ColorBlack).SetBorder(true).SetTitle(" Pods ").SetBorderPadding(0, 0, 1, 1)
+
+	return tree
+}
+
+// createAlertsView creates and configures a text view widget for listing the policy
+// violations and process drift alerts of every container.
+func createAlertsView(app *tview.Application, table *tview.Table) *tview.TextView {
+	textView := tview.NewTextView().SetDynamicColors(true)
+	textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyLeft:
+			app.SetFocus(table)
+		}
+		return event
+	})
+
+	textView.SetBackgroundColor(tcell.ColorBlack).SetBorder(true).SetTitle(" Alerts ").SetBorderPadding(0, 0, 1, 1)
+
+	return textView
+}
+
+// tableSortMode selects how the rows of the containers table are ordered.
+type tableSortMode int
+
+const (
+	tableSortNamespace tableSortMode = iota
+	tableSortDisk
+)
+
+// tableSort is the order currently applied to the containers table
+var tableSort = tableSortNamespace
+
+// next returns the sort mode that follows m when toggling.
+func (m tableSortMode) next() tableSortMode {
+	return (m + 1) % 2
+}
+
+// createDriftView creates and configures a text view widget for the filesystem drift page.
+// Escape returns to the main page.
+func createDriftView(app *tview.Application, pages *tview.Pages, table *tview.Table) *tview.TextView {
+	textView := tview.NewTextView().SetDynamicColors(true)
+	textView.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			pages.SwitchToPage("main")
+			app.SetFocus(table)
+		}
+	})
+
+	textView.SetBackgroundColor(tcell.ColorBlack).SetBorder(true).SetTitle(" Filesystem Drift ").SetBorderPadding(0, 0, 1, 1)
+
+	return textView
+}
+
+// refreshTable fetches the containers and rebuilds the containers table, the pod tree and
+// the list of alerts.
+func refreshTable(table *tview.Table, detailsTextView *tview.TextView, alertsView *tview.TextView, podTree *tview.TreeView) {
 	containers, err := GetContainers(true)
 	if err != nil {
 		panic(err)
@@ -53,37 +155,205 @@ func refreshTable(table *tview.Table, detailsTextView *tview.TextView) {
 
 	table.Clear()
 
+	// Group containers by namespace and keep a stable order between refreshes, unless
+	// the table is sorted by disk usage
+	sort.Slice(containers, func(i, j int) bool {
+		if tableSort == tableSortDisk {
+			iDisk, jDisk := totalDiskUsage(containers[i].DiskUsage), totalDiskUsage(containers[j].DiskUsage)
+			if iDisk != jDisk {
+				return iDisk > jDisk
+			}
+		}
+		if containers[i].Namespace != containers[j].Namespace {
+			return containers[i].Namespace < containers[j].Namespace
+		}
+		return containers[i].PID < containers[j].PID
+	})
+
 	// Table headers
 	table.SetCell(0, 0, tview.NewTableCell("PID").SetAlign(tview.AlignCenter))
-	table.SetCell(0, 1, tview.NewTableCell("Owner").SetAlign(tview.AlignCenter))
-	table.SetCell(0, 2, tview.NewTableCell("Created").SetAlign(tview.AlignCenter))
-	table.SetCell(0, 3, tview.NewTableCell("Status").SetAlign(tview.AlignCenter))
+	table.SetCell(0, 1, tview.NewTableCell("Namespace").SetAlign(tview.AlignCenter))
+	table.SetCell(0, 2, tview.NewTableCell("Owner").SetAlign(tview.AlignCenter))
+	table.SetCell(0, 3, tview.NewTableCell("Created").SetAlign(tview.AlignCenter))
+	table.SetCell(0, 4, tview.NewTableCell("Status").SetAlign(tview.AlignCenter))
+	table.SetCell(0, 5, tview.NewTableCell("RX").SetAlign(tview.AlignCenter))
+	table.SetCell(0, 6, tview.NewTableCell("TX").SetAlign(tview.AlignCenter))
+	table.SetCell(0, 7, tview.NewTableCell("Risk").SetAlign(tview.AlignCenter))
+	table.SetCell(0, 8, tview.NewTableCell("Policy").SetAlign(tview.AlignCenter))
+	diskHeader := "Disk"
+	if tableSort == tableSortDisk {
+		diskHeader = "Disk ▼"
+	}
+	table.SetCell(0, 9, tview.NewTableCell(diskHeader).SetAlign(tview.AlignCenter))
 
-	layout := "2006-01-02T15:04:05.999999999Z"
 	for i, container := range containers {
-		t, err := time.Parse(layout, container.Created)
+		t, err := time.Parse(time.RFC3339Nano, container.Created)
 		if err != nil {
 			panic(err)
 		}
 		formatted := t.Format("02-Jan-2006-03:04 PM")
-		table.SetCell(i+1, 0, tview.NewTableCell(strconv.Itoa(container.PID)).SetAlign(tview.AlignCenter))
-		table.SetCell(i+1, 1, tview.NewTableCell(container.Owner).SetAlign(tview.AlignCenter))
-		table.SetCell(i+1, 2, tview.NewTableCell(formatted).SetAlign(tview.AlignCenter))
-		table.SetCell(i+1, 3, tview.NewTableCell(container.Status).SetAlign(tview.AlignCenter))
+		// Reference the container ID so the row can be looked up in the cache
+		table.SetCell(i+1, 0, tview.NewTableCell(strconv.Itoa(container.PID)).SetAlign(tview.AlignCenter).SetReference(container.ID))
+		table.SetCell(i+1, 1, tview.NewTableCell(container.Namespace).SetAlign(tview.AlignCenter))
+		table.SetCell(i+1, 2, tview.NewTableCell(container.Owner).SetAlign(tview.AlignCenter))
+		table.SetCell(i+1, 3, tview.NewTableCell(formatted).SetAlign(tview.AlignCenter))
+		table.SetCell(i+1, 4, tview.NewTableCell(container.Status).SetAlign(tview.AlignCenter))
+		table.SetCell(i+1, 5, tview.NewTableCell(formatBytes(container.NetworkUsage.ReceiveRate)+"/s").SetAlign(tview.AlignCenter))
+		table.SetCell(i+1, 6, tview.NewTableCell(formatBytes(container.NetworkUsage.TransmitRate)+"/s").SetAlign(tview.AlignCenter))
+		severity := highestSeverity(container.Findings)
+		table.SetCell(i+1, 7, tview.NewTableCell(severity.String()).SetAlign(tview.AlignCenter).SetTextColor(tcell.GetColor(severityColor(severity))))
+		if len(container.Violations) > 0 {
+			table.SetCell(i+1, 8, tview.NewTableCell(fmt.Sprintf("%d violated", len(container.Violations))).SetAlign(tview.AlignCenter).SetTextColor(tcell.ColorRed))
+		} else {
+			table.SetCell(i+1, 8, tview.NewTableCell("ok").SetAlign(tview.AlignCenter).SetTextColor(tcell.ColorGreen))
+		}
+		table.SetCell(i+1, 9, tview.NewTableCell(formatBytes(float64(totalDiskUsage(container.DiskUsage)))).SetAlign(tview.AlignCenter))
 	}
 
+	showPods(containers, podTree)
+	showAlerts(containers, alertsView)
+}
+
+// selectContainerRow selects the table row of a container, so the rest of the UI follows
+// a selection made in the pod tree.
+func selectContainerRow(table *tview.Table, id string) bool {
+	for row := 1; row < table.GetRowCount(); row++ {
+		if rowID, ok := table.GetCell(row, 0).GetReference().(string); ok && rowID == id {
+			table.Select(row, 0)
+			return true
+		}
+	}
+	return false
 }
 
-func updateDetails(table *tview.Table, detailsTextView *tview.TextView) {
+// showPods rebuilds the pod tree: Kubernetes namespaces, their pods with the aggregated
+// usage of their containers, then the containers. Sandbox (pause) containers are folded
+// into their pod's node, and containers outside any pod are listed last.
+func showPods(containers []Container, tree *tview.TreeView) {
+	// Keep the selection on the same node when the tree is rebuilt
+	selected := ""
+	if node := tree.GetCurrentNode(); node != nil {
+		if ref, ok := node.GetReference().(podTreeRef); ok {
+			selected = ref.key
+		}
+	}
+
+	pods, standalone := groupPods(containers)
+	root := tview.NewTreeNode("Kubernetes").SetSelectable(false).SetColor(tcell.ColorYellow)
+
+	var current *tview.TreeNode
+	addNode := func(parent *tview.TreeNode, text string, ref podTreeRef, color tcell.Color) *tview.TreeNode {
+		node := tview.NewTreeNode(text).
+			SetReference(ref).
+			SetColor(color).
+			SetExpanded(!collapsedPodNodes[ref.key])
+		parent.AddChild(node)
+		if ref.key == selected {
+			current = node
+		}
+		return node
+	}
+
+	var namespaceNode *tview.TreeNode
+	namespace := ""
+	for i, pod := range pods {
+		if namespaceNode == nil || pod.Namespace != namespace {
+			namespace = pod.Namespace
+			count := 0
+			for _, other := range pods[i:] {
+				if other.Namespace == namespace {
+					count++
+				}
+			}
+			namespaceNode = addNode(root, fmt.Sprintf("%s (%d pods)", tview.Escape(namespace), count), podTreeRef{key: "ns/" + namespace}, tcell.ColorGreen)
+		}
+
+		ref := podTreeRef{key: "pod/" + pod.Namespace + "/" + pod.Name + "/" + pod.SandboxID}
+		sandbox := ""
+		if pod.Sandbox != nil {
+			ref.containerID = pod.Sandbox.ID
+			sandbox = fmt.Sprintf(", sandbox pid %d", pod.Sandbox.PID)
+		}
+		text := fmt.Sprintf("%-40s CPU %6.2f%%  MEM %10s  (%d containers%s)",
+			tview.Escape(pod.Name), pod.CPUUsage, formatBytes(float64(pod.MemoryUsage)*1024), len(pod.Containers), sandbox)
+		podNode := addNode(namespaceNode, text, ref, tcell.ColorDarkCyan)
+
+		for _, container := range pod.Containers {
+			addNode(podNode, containerNodeText(container), podTreeRef{key: "ctr/" + container.ID, containerID: container.ID}, tcell.ColorWhite)
+		}
+	}
+
+	if len(standalone) > 0 {
+		otherNode := addNode(root, fmt.Sprintf("not in a pod (%d containers)", len(standalone)), podTreeRef{key: "standalone"}, tcell.ColorGray)
+		for _, container := range standalone {
+			addNode(otherNode, containerNodeText(container), podTreeRef{key: "ctr/" + container.ID, containerID: container.ID}, tcell.ColorWhite)
+		}
+	}
+
+	if current == nil && len(root.GetChildren()) > 0 {
+		current = root.GetChildren()[0]
+	}
+
+	tree.SetRoot(root).SetCurrentNode(current)
+	tree.SetTitle(fmt.Sprintf(" Pods (%d) ", len(pods)))
+}
+
+// containerNodeText formats a container's line in the pod tree.
+func containerNodeText(container Container) string {
+	return fmt.Sprintf("%-32s pid %-8d %-8s CPU %6.2f%%  MEM %10s",
+		tview.Escape(containerName(container)), container.PID, container.Status,
+		container.ResourceUsage.CPUUsage, formatBytes(float64(container.ResourceUsage.MemoryInUse())*1024))
+}
+
+// showAlerts lists the policy violations and process drift alerts of every container,
+// in table order.
+func showAlerts(containers []Container, alertsView *tview.TextView) {
+	var details strings.Builder
+	for _, container := range containers {
+		for _, violation := range container.Violations {
+			details.WriteString(fmt.Sprintf("[%s]%-8s[-] [::b]%s[::-] %s/%s (pid %d): %s\n",
+				severityColor(violation.Severity), strings.ToUpper(violation.Severity.String()), violation.Check,
+				container.Namespace, shortContainerID(container.ID), container.PID, tview.Escape(violation.Message)))
+		}
+		for _, alert := range container.ProcessAlerts {
+			details.WriteString(fmt.Sprintf("[%s]%-8s[-] [::b]process-drift[::-] %s/%s (pid %d): %s\n",
+				severityColor(alert.Severity), strings.ToUpper(alert.Severity.String()),
+				container.Namespace, shortContainerID(container.ID), container.PID, tview.Escape(alert.String())))
+		}
+	}
+
+	if details.Len() == 0 {
+		if len(policies) == 0 {
+			details.WriteString("[green]No process drift[-], no policy rules configured\n")
+		} else {
+			details.WriteString(fmt.Sprintf("[green]No process drift and no violations of %d rules[-]\n", len(policies)))
+		}
+	}
+
+	alertsView.SetText(details.String())
+}
+
+// selectedContainer looks up the container selected in the table in the cache.
+func selectedContainer(table *tview.Table) (Container, bool) {
 	row, _ := table.GetSelection()
 
-	pid := table.GetCell(row, 0).Text
+	id, ok := table.GetCell(row, 0).GetReference().(string)
+	if !ok {
+		return Container{}, false
+	}
+
 	cacheMutex.RLock()
-	container, exists := containerCache[pid]
+	container, exists := containerCache[id]
 	cacheMutex.RUnlock()
 
-	if exists {
+	return container, exists
+}
+
+// updateDetails shows the details and processes of the container selected in the table.
+func updateDetails(table *tview.Table, detailsTextView *tview.TextView, processTree *tview.TreeView) {
+	if container, ok := selectedContainer(table); ok {
 		showDetails(container, detailsTextView)
+		showProcesses(container, processTree)
 	}
 }
 
@@ -95,17 +365,32 @@ func showDetails(container Container, detailsTextView *tview.TextView) {
 	// Add container information to the details.
 	details.WriteString(showContainerInfo(container))
 
+	// Add audit findings to the details.
+	details.WriteString(showFindings(container))
+
+	// Add process drift alerts to the details.
+	details.WriteString(showProcessDrift(container))
+
 	// Add resource usage information to the details.
 	details.WriteString(showResourceUsage(container))
 
+	// Add resource limits information to the details.
+	details.WriteString(showResourceLimits(container))
+
 	// Add network usage information to the details.
 	details.WriteString(showNetworkUsage(container))
 
-	// Add exposed ports information to the details.
-	details.WriteString(showExposedPorts(container))
+	// Add network interfaces information to the details.
+	details.WriteString(showNetworkInterfaces(container))
+
+	// Add connections information to the details.
+	details.WriteString(showConnections(container))
 
-	// Add mounted volumes information to the details.
-	details.WriteString(showMountedVolumes(container))
+	// Add mounts information to the details.
+	details.WriteString(showMounts(container))
+
+	// Add disk usage information to the details.
+	details.WriteString(showDiskUsage(container))
 
 	// Add Kubernetes metadata information to the details.
 	details.WriteString(showKubernetesMetadata(container))
@@ -116,8 +401,14 @@ func showDetails(container Container, detailsTextView *tview.TextView) {
 	// Add environment variables information to the details.
 	details.WriteString(showEnvironmentVariables(container))
 
-	// Add security profiles information to the details.
-	details.WriteString(showSecurityProfiles(container))
+	// Add namespaces information to the details.
+	details.WriteString(showNamespaces(container))
+
+	// Add security information to the details.
+	details.WriteString(showSecurity(container))
+
+	// Add the bundle's OCI spec to the details.
+	details.WriteString(showSpec(container))
 
 	// Set the TextView's text to the accumulated details.
 	detailsTextView.SetText(details.String())
@@ -128,61 +419,447 @@ func showContainerInfo(container Container) string {
 	// Initialize details string with PID first
 	details := fmt.Sprintf("[::b]=== Container Info ===[::-]\n[::b]Container PID:[::-] %d\n", container.PID)
 
-	// Check if the "io.kubernetes.cri.image-name" key exists in annotations
-	if imageName, ok := container.Annotations["io.kubernetes.cri.image-name"]; ok {
-		details += fmt.Sprintf("[::b]Image Name:[::-] %s\n", imageName)
+	// Not every runtime reports the image the container was created from
+	if container.Image != "" {
+		details += fmt.Sprintf("[::b]Image Name:[::-] %s\n", container.Image)
 	}
 
 	// Append the rest of the details
-	details += fmt.Sprintf("[::b]ID:[::-] %s\n[::b]Status:[::-] %s\n[::b]Created:[::-] %s\n[::b]RootFS:[::-] %s\n[::b]CMD:[::-] %s\n",
-		container.ID, container.Status, container.Created, container.RootFS, container.StartCommand)
+	details += fmt.Sprintf("[::b]ID:[::-] %s\n[::b]Namespace:[::-] %s\n[::b]%s:[::-] %s\n[::b]Status:[::-] %s\n[::b]Created:[::-] %s\n[::b]RootFS:[::-] %s\n[::b]CMD:[::-] %s\n",
+		container.ID, container.Namespace, runtimeRootLabel(), container.Root, container.Status, container.Created, container.RootFS, container.StartCommand)
+
+	if container.PopulateError != "" {
+		details += fmt.Sprintf("[red::b]Inspection failed:[-::-] %s\n", tview.Escape(container.PopulateError))
+	}
 
 	return details
 }
 
+// runtimeRootLabel names what Container.Root holds for the selected runtime backend.
+func runtimeRootLabel() string {
+	switch containerRuntime.Name() {
+	case "runc":
+		return "Runc Root"
+	case "docker":
+		return "Docker Socket"
+	case "cri":
+		return "CRI Endpoint"
+	default:
+		return "Runtime Root"
+	}
+}
+
+// severityColor returns the color findings of a severity are highlighted with.
+func severityColor(severity Severity) string {
+	switch severity {
+	case SeverityCritical:
+		return "red"
+	case SeverityHigh:
+		return "orange"
+	case SeverityMedium:
+		return "yellow"
+	case SeverityLow:
+		return "lightskyblue"
+	default:
+		return "green"
+	}
+}
+
+// showFindings displays the findings of the security audit, most severe first.
+func showFindings(container Container) string {
+	details := "\n[::b]=== Audit Findings ===[::-]\n"
+
+	if len(container.Findings) == 0 {
+		return details + "[green]No findings[-]\n"
+	}
+
+	for _, finding := range container.Findings {
+		details += fmt.Sprintf("[%s]%-8s[-] [::b]%s:[::-] %s\n",
+			severityColor(finding.Severity), strings.ToUpper(finding.Severity.String()), finding.Check, tview.Escape(finding.Message))
+	}
+
+	return details
+}
+
+// showProcessDrift displays the processes whose executable drifted from the container's
+// baseline, the executables running when Tachyon first observed it.
+func showProcessDrift(container Container) string {
+	details := "\n[::b]=== Process Drift ===[::-]\n"
+
+	if len(container.ProcessAlerts) == 0 {
+		return details + "[green]No new executables since first observation[-]\n"
+	}
+
+	for _, alert := range container.ProcessAlerts {
+		details += fmt.Sprintf("[%s]%-8s[-] %s [::b]%s[::-] (pid %d): %s\n  %s\n",
+			severityColor(alert.Severity), strings.ToUpper(alert.Severity.String()), alert.DetectedAt.Format("15:04:05"),
+			tview.Escape(alert.Exe), alert.PID, strings.Join(alert.Reasons, ", "), tview.Escape(alert.CMD))
+	}
+
+	return details
+}
+
+// memoryUsageKeys orders the memory figures shown in the details, cgroup totals first
+var memoryUsageKeys = []string{"Current", "Anon", "File", "RSS", "VMS"}
+
 // showResourceUsage displays resource usage information.
 func showResourceUsage(container Container) string {
 	details := "\n[::b]=== Resource Usage ===[::-]\n"
-	details += fmt.Sprintf("[::b]CPU Usage:[::-] %.2f%%\n", container.ResourceUsage.CPUUsage)
-	details += fmt.Sprintf("[::b]RSS Memory:[::-] %d kB\n[::b]VMS Memory:[::-] %d kB\n", container.ResourceUsage.MemoryUsage["RSS"], container.ResourceUsage.MemoryUsage["VMS"])
+	details += fmt.Sprintf("[::b]CPU Usage:[::-] %.2f%% (%.2f cores)\n", container.ResourceUsage.CPUUsage, container.ResourceUsage.CPUCores)
+	for _, key := range memoryUsageKeys {
+		if value, ok := container.ResourceUsage.MemoryUsage[key]; ok {
+			details += fmt.Sprintf("[::b]%s Memory:[::-] %d kB\n", key, value)
+		}
+	}
 	details += fmt.Sprintf("[::b]Swap Usage:[::-] %d kB\n", container.ResourceUsage.SwapUsage)
+	details += fmt.Sprintf("[::b]IO Read:[::-] %d bytes\n[::b]IO Written:[::-] %d bytes\n", container.ResourceUsage.IOReadBytes, container.ResourceUsage.IOWriteBytes)
+	details += fmt.Sprintf("[::b]IO Rate:[::-] %s/s read, %s/s written\n", formatBytes(container.ResourceUsage.IOReadRate), formatBytes(container.ResourceUsage.IOWriteRate))
+	details += fmt.Sprintf("[::b]Processes:[::-] %d\n", container.ResourceUsage.Pids)
+
+	// Draw the CPU and memory trends from the container's history
+	history := GetHistory(container.ID)
+	cpu := historyValues(history, func(s MetricSample) float64 { return s.CPUUsage })
+	memory := historyValues(history, func(s MetricSample) float64 { return float64(s.MemoryUsage) })
+	details += fmt.Sprintf("[::b]CPU Trend (%s):[::-] [green]%s[-]\n", historySpan(history), sparkline(cpu))
+	details += fmt.Sprintf("[::b]Memory Trend (%s):[::-] [blue]%s[-]\n", historySpan(history), sparkline(memory))
 
 	return details
 }
 
+// showResourceLimits displays the resource limits and how much of each limit is in use.
+func showResourceLimits(container Container) string {
+	limits := container.ResourceLimits
+	usage := container.ResourceUsage
+
+	details := "\n[::b]=== Resource Limits ===[::-]\n"
+
+	if limits.CPULimit > 0 {
+		details += fmt.Sprintf("[::b]CPU Limit:[::-] %.2f cores%s\n", limits.CPULimit, limitUsage(usage.CPUCores, limits.CPULimit))
+	} else {
+		details += "[::b]CPU Limit:[::-] unlimited\n"
+	}
+
+	if limits.MemoryLimit > 0 {
+		details += fmt.Sprintf("[::b]Memory Limit:[::-] %d kB%s\n", limits.MemoryLimit, limitUsage(float64(usage.MemoryInUse()), float64(limits.MemoryLimit)))
+	} else {
+		details += "[::b]Memory Limit:[::-] unlimited\n"
+	}
+
+	// The disk IO limit is the lowest of the read and write limits, so it is compared
+	// with the busier direction
+	if limits.DiskIOLimit > 0 {
+		ioRate := usage.IOReadRate
+		if usage.IOWriteRate > ioRate {
+			ioRate = usage.IOWriteRate
+		}
+		details += fmt.Sprintf("[::b]Disk IO Limit:[::-] %d MB/s%s\n", limits.DiskIOLimit, limitUsage(ioRate, float64(limits.DiskIOLimit)*1e6))
+	} else {
+		details += "[::b]Disk IO Limit:[::-] unlimited\n"
+	}
+
+	// The network limit is the pod's egress bandwidth, so it is compared with the transmit rate
+	if limits.NetworkLimit > 0 {
+		details += fmt.Sprintf("[::b]Network Limit:[::-] %s/s%s\n", formatBytes(float64(limits.NetworkLimit)), limitUsage(container.NetworkUsage.TransmitRate, float64(limits.NetworkLimit)))
+	} else {
+		details += "[::b]Network Limit:[::-] unlimited\n"
+	}
+
+	return details
+}
+
+// limitUsage formats how much of a limit is used, highlighting usage close to the limit.
+func limitUsage(used, limit float64) string {
+	percentage := used / limit * 100
+
+	color := "green"
+	if percentage >= 90 {
+		color = "red"
+	} else if percentage >= 75 {
+		color = "yellow"
+	}
+
+	return fmt.Sprintf(" ([%s]%.1f%% used[-])", color, percentage)
+}
+
 // showNetworkUsage displays network usage information.
 func showNetworkUsage(container Container) string {
 	details := "\n[::b]=== Network Usage ===[::-]\n"
-	details += fmt.Sprintf("[::b]Received Bytes:[::-] %d\n[::b]Transmitted Bytes:[::-] %d\n", container.NetworkUsage.ReceivedBytes, container.NetworkUsage.TransmittedBytes)
+	usage := container.NetworkUsage
+	details += fmt.Sprintf("[::b]Received:[::-] %s (%s/s)\n[::b]Transmitted:[::-] %s (%s/s)\n",
+		formatBytes(float64(usage.ReceivedBytes)), formatBytes(usage.ReceiveRate), formatBytes(float64(usage.TransmittedBytes)), formatBytes(usage.TransmitRate))
+
+	// Per-interface table, packets, errors and drops shown as received/transmitted
+	if len(usage.Interfaces) > 0 {
+		details += fmt.Sprintf("[::b]%-12s %12s %12s %12s %12s %17s %11s %11s[::-]\n", "Interface", "RX/s", "TX/s", "RX", "TX", "Packets", "Errors", "Drops")
+		for _, stats := range usage.Interfaces {
+			details += fmt.Sprintf("%-12s %12s %12s %12s %12s %17s %11s %11s\n",
+				stats.Name,
+				formatBytes(stats.ReceiveRate),
+				formatBytes(stats.TransmitRate),
+				formatBytes(float64(stats.RxBytes)),
+				formatBytes(float64(stats.TxBytes)),
+				fmt.Sprintf("%d/%d", stats.RxPackets, stats.TxPackets),
+				fmt.Sprintf("%d/%d", stats.RxErrors, stats.TxErrors),
+				fmt.Sprintf("%d/%d", stats.RxDropped, stats.TxDropped))
+		}
+	}
+
+	// Draw the receive and transmit rates from the container's history
+	history := GetHistory(container.ID)
+	rx := historyRates(history, func(s MetricSample) float64 { return float64(s.RxBytes) })
+	tx := historyRates(history, func(s MetricSample) float64 { return float64(s.TxBytes) })
+	details += fmt.Sprintf("[::b]RX Trend (%s):[::-] [green]%s[-] %s/s\n", historySpan(history), sparkline(rx), formatBytes(lastValue(rx)))
+	details += fmt.Sprintf("[::b]TX Trend (%s):[::-] [yellow]%s[-] %s/s\n", historySpan(history), sparkline(tx), formatBytes(lastValue(tx)))
 
 	return details
 }
 
-// showExposedPorts displays information about exposed ports.
-func showExposedPorts(container Container) string {
-	details := "\n[::b]=== Exposed Ports ===[::-]\n"
+// showNetworkInterfaces displays the interfaces and routes of the container's network namespace.
+func showNetworkInterfaces(container Container) string {
+	details := "\n[::b]=== Network Interfaces ===[::-]\n"
 
-	for _, port := range container.ExposedPorts {
-		details += fmt.Sprintf("[::b]Port:[::-] %d\n", port)
+	for _, iface := range container.Interfaces {
+		details += fmt.Sprintf("[::b]%s:[::-]", iface.Name)
+		if iface.MTU > 0 {
+			details += fmt.Sprintf(" mtu %d", iface.MTU)
+		}
+		if iface.MAC != "" {
+			details += fmt.Sprintf(" mac %s", iface.MAC)
+		}
+		if iface.Flags != "" {
+			details += fmt.Sprintf(" <%s>", iface.Flags)
+		}
+		details += "\n"
+		for _, address := range iface.Addresses {
+			details += fmt.Sprintf("  %s\n", address)
+		}
+	}
+
+	if len(container.Routes) > 0 {
+		details += fmt.Sprintf("[::b]%-40s %-26s %-12s %s[::-]\n", "Destination", "Gateway", "Interface", "Metric")
+		for _, route := range container.Routes {
+			gateway := route.Gateway
+			if gateway == "" {
+				gateway = "-"
+			}
+			details += fmt.Sprintf("%-40s %-26s %-12s %d\n", route.Destination, gateway, route.Interface, route.Metric)
+		}
 	}
 
 	return details
 }
 
-// showMountedVolumes displays information about mounted volumes.
-func showMountedVolumes(container Container) string {
-	volumes := container.MountedVolumes
+// socketFilterMode selects which sockets the connections section shows.
+type socketFilterMode int
 
-	details := "\n[::b]=== Mounted Volumes ===[::-]\n"
+const (
+	socketFilterAll socketFilterMode = iota
+	socketFilterListening
+	socketFilterEstablished
+)
 
-	// Display volumes in a table format
-	for i := 0; i < len(volumes); i += 2 {
-		// If there's a next volume, add it side by side
-		if i+1 < len(volumes) {
-			details += fmt.Sprintf("%-20s %-20s\n", volumes[i], volumes[i+1])
-		} else {
-			details += fmt.Sprintf("%-20s\n", volumes[i])
+// socketFilter is the filter currently applied to the connections section
+var socketFilter = socketFilterAll
+
+func (m socketFilterMode) String() string {
+	switch m {
+	case socketFilterListening:
+		return "listening"
+	case socketFilterEstablished:
+		return "established"
+	default:
+		return "all"
+	}
+}
+
+// next returns the filter that follows m when cycling through the filters.
+func (m socketFilterMode) next() socketFilterMode {
+	return (m + 1) % 3
+}
+
+// matches reports whether a socket passes the filter. Unconnected UDP sockets are
+// waiting for datagrams, so they count as listening.
+func (m socketFilterMode) matches(socket Socket) bool {
+	switch m {
+	case socketFilterListening:
+		return socket.State == "LISTEN" || (socket.State == "UNCONN" && socket.Protocol != "unix")
+	case socketFilterEstablished:
+		return socket.State == "ESTABLISHED"
+	default:
+		return true
+	}
+}
+
+// showConnections displays the sockets in the container's network namespace.
+func showConnections(container Container) string {
+	details := fmt.Sprintf("\n[::b]=== Connections (%s) ===[::-]\n", socketFilter)
+
+	details += fmt.Sprintf("[::b]%-6s %-46s %-46s %-12s %-20s %s[::-]\n", "Proto", "Local Address", "Remote Address", "State", "Process", "Inode")
+	for _, socket := range container.Sockets {
+		if !socketFilter.matches(socket) {
+			continue
+		}
+
+		process := "-"
+		if socket.PID > 0 {
+			process = fmt.Sprintf("%s/%d", socket.Process, socket.PID)
+		}
+		local := socket.LocalAddress
+		if local == "" {
+			local = "-"
+		}
+		remote := socket.RemoteAddress
+		if remote == "" {
+			remote = "-"
+		}
+
+		details += fmt.Sprintf("%-6s %-46s %-46s %-12s %-20s %d\n", socket.Protocol, tview.Escape(local), tview.Escape(remote), socket.State, tview.Escape(process), socket.Inode)
+	}
+
+	return details
+}
+
+// showPseudoMounts shows the pseudo filesystems, such as proc and cgroup, in the mounts section
+var showPseudoMounts = false
+
+// showMounts displays the container's mounts, with pseudo filesystems hidden unless
+// showPseudoMounts is set.
+func showMounts(container Container) string {
+	var rows strings.Builder
+	hidden := 0
+	for _, mount := range container.Mounts {
+		if mount.Pseudo && !showPseudoMounts {
+			hidden++
+			continue
+		}
+
+		// Pad before coloring so the color tags do not break the alignment
+		mode := "rw  "
+		if mount.ReadOnly {
+			mode = "[yellow]ro  [-]"
+		}
+
+		origin := mount.Source
+		if mount.HostPath != "" {
+			origin = mount.HostPath
+		}
+
+		kind := "runtime"
+		switch {
+		case mount.KubernetesVolume:
+			kind = "[green]k8s volume[-]"
+		case mount.Pseudo:
+			kind = "pseudo"
+		case mount.Target == "/":
+			kind = "rootfs"
+		}
+
+		rows.WriteString(fmt.Sprintf("%-40s %-60s %-10s %s %-14s %s\n",
+			tview.Escape(mount.Target), tview.Escape(origin), mount.FSType, mode, mount.Propagation, kind))
+	}
+
+	details := "\n[::b]=== Mounts ===[::-]\n"
+	if hidden > 0 {
+		details = fmt.Sprintf("\n[::b]=== Mounts (%d pseudo filesystems hidden, press m to show) ===[::-]\n", hidden)
+	}
+	details += fmt.Sprintf("[::b]%-40s %-60s %-10s %-4s %-14s %s[::-]\n", "Target", "Source", "Type", "Mode", "Propagation", "Kind")
+
+	return details + rows.String()
+}
+
+// showDiskUsage displays the disk space and inodes used by the container's writable layer
+// and volumes.
+func showDiskUsage(container Container) string {
+	details := fmt.Sprintf("\n[::b]=== Disk Usage (%s) ===[::-]\n", formatBytes(float64(totalDiskUsage(container.DiskUsage))))
+
+	details += fmt.Sprintf("[::b]%-40s %12s %10s %-10s %s[::-]\n", "Target", "Size", "Inodes", "Scanned", "Host Path")
+	for _, usage := range container.DiskUsage {
+		target := usage.Target
+		if target == "/" {
+			target = "/ (writable layer)"
 		}
+
+		if usage.ScannedAt.IsZero() {
+			details += fmt.Sprintf("%-40s %12s %10s %-10s %s\n", tview.Escape(target), "-", "-", "scanning", tview.Escape(usage.Path))
+			continue
+		}
+		scanned := time.Since(usage.ScannedAt).Truncate(time.Second).String() + " ago"
+		details += fmt.Sprintf("%-40s %12s %10d %-10s %s\n",
+			tview.Escape(target), formatBytes(float64(usage.Bytes)), usage.Inodes, scanned, tview.Escape(usage.Path))
+	}
+
+	return details
+}
+
+// driftGeneration identifies the latest drift scan, so a slow scan of a previously
+// selected container does not overwrite the current one
+var driftGeneration int
+
+// showDrift scans the selected container's writable layer in the background and shows
+// the files it added, modified and deleted compared to its image.
+func showDrift(app *tview.Application, container Container, driftView *tview.TextView) {
+	driftGeneration++
+	generation := driftGeneration
+
+	driftView.SetTitle(fmt.Sprintf(" Filesystem Drift: %s (pid %d, Esc to return) ", shortContainerID(container.ID), container.PID))
+	driftView.SetText("Scanning the writable layer...\n").ScrollToBeginning()
+
+	go func() {
+		changes, err := container.getFilesystemDrift()
+		app.QueueUpdateDraw(func() {
+			if generation != driftGeneration {
+				return
+			}
+			if err != nil {
+				driftView.SetText(fmt.Sprintf("[red]Failed to scan the writable layer: %s[-]\n", tview.Escape(err.Error())))
+				return
+			}
+			driftView.SetText(formatDrift(changes))
+		})
+	}()
+}
+
+// driftColors are the colors each kind of filesystem change is shown with
+var driftColors = map[string]string{
+	"added":    "green",
+	"modified": "yellow",
+	"deleted":  "red",
+	"replaced": "orange",
+}
+
+// formatDrift lists filesystem changes, flagging executables dropped into the container.
+func formatDrift(changes []FileChange) string {
+	counts := make(map[string]int)
+	executables := 0
+	for _, change := range changes {
+		counts[change.Kind]++
+		if change.Executable() {
+			executables++
+		}
+	}
+
+	details := fmt.Sprintf("[::b]Added:[::-] %d  [::b]Modified:[::-] %d  [::b]Deleted:[::-] %d  [::b]Replaced directories:[::-] %d  [::b]Executables:[::-] %d\n\n",
+		counts["added"], counts["modified"], counts["deleted"], counts["replaced"], executables)
+	details += fmt.Sprintf("[::b]%-9s %-11s %10s %-20s %s[::-]\n", "Change", "Mode", "Size", "Modified", "Path")
+
+	for _, change := range changes {
+		mode, size := "-", "-"
+		if change.Kind != "deleted" {
+			mode = change.Mode.String()
+			size = formatBytes(float64(change.Size))
+		}
+
+		path := tview.Escape(change.Path)
+		if change.Executable() {
+			path = "[red::b]" + path + "[-::-] (executable)"
+		}
+
+		details += fmt.Sprintf("[%s]%-9s[-] %-11s %10s %-20s %s\n",
+			driftColors[change.Kind], change.Kind, mode, size, change.ModTime.Format("2006-01-02 15:04:05"), path)
+	}
+
+	if len(changes) == 0 {
+		details += "No changes to the image\n"
 	}
 
 	return details
@@ -192,6 +869,16 @@ func showMountedVolumes(container Container) string {
 func showKubernetesMetadata(container Container) string {
 	details := "\n[::b]=== Kubernetes Metadata ===[::-]\n"
 
+	if metadata, ok := getPodMetadata(container); ok {
+		details += fmt.Sprintf("[::b]Pod:[::-] %s/%s\n", metadata.Namespace, metadata.Name)
+		if metadata.Sandbox {
+			details += "[::b]Container:[::-] sandbox\n"
+		} else {
+			details += fmt.Sprintf("[::b]Container:[::-] %s\n", metadata.ContainerName)
+		}
+		details += fmt.Sprintf("[::b]Sandbox ID:[::-] %s\n", metadata.SandboxID)
+	}
+
 	// Extract keys from the map and sort them alphabetically
 	var keys []string
 	for key := range container.Annotations {
@@ -212,31 +899,387 @@ func showKubernetesMetadata(container Container) string {
 func showOpenFiles(container Container) string {
 	details := "\n[::b]=== Open Files ===[::-]\n"
 	for _, file := range container.OpenFiles {
-		details += fmt.Sprintf("[::b]Command:[::-] %s, [::b]PID:[::-] %s, [::b]Name:[::-] %s\n", file.Command, file.PID, file.Name)
+		details += fmt.Sprintf("[::b]Command:[::-] %s, [::b]PID:[::-] %s, [::b]FD:[::-] %s, [::b]Type:[::-] %s, [::b]Pos:[::-] %d, [::b]Flags:[::-] %s, [::b]Name:[::-] %s\n",
+			file.Command, file.PID, file.FD, file.Type, file.Pos, file.Flags, tview.Escape(file.Name))
 	}
 
 	return details
 }
 
-// showEnvironmentVariables displays environment variables information.
+// showEnvironmentVariables displays environment variables information, masking the
+// values of variables that look like secrets unless they were revealed.
 func showEnvironmentVariables(container Container) string {
 	details := "\n[::b]=== Environment Variables ===[::-]\n"
 
 	for _, envVar := range container.EnvVariables {
-		if strings.Contains(envVar, "=") {
-			details += fmt.Sprintf("%s\n", envVar)
+		key, value, ok := strings.Cut(envVar, "=")
+		if !ok {
+			continue
+		}
+
+		switch {
+		case !isSecret(key, value):
+			details += fmt.Sprintf("%s=%s\n", tview.Escape(key), tview.Escape(value))
+		case isRevealed(container, key):
+			details += fmt.Sprintf("%s=[yellow]%s[-] (revealed)\n", tview.Escape(key), tview.Escape(value))
+		default:
+			details += fmt.Sprintf("%s=[red]********[-] (masked, press v to reveal)\n", tview.Escape(key))
 		}
 	}
 
 	return details
 }
 
-// showSecurityProfiles displays security profiles information.
-func showSecurityProfiles(container Container) string {
-	details := "\n[::b]=== Security Profiles ===[::-]\n"
+// showNamespaces displays the container's namespaces, highlighting the ones shared with
+// the host or with other containers, and its user namespace mappings.
+func showNamespaces(container Container) string {
+	details := "\n[::b]=== Namespaces ===[::-]\n"
+
+	details += fmt.Sprintf("[::b]%-8s %-12s %s[::-]\n", "Type", "Inode", "Shared With")
+	for _, namespace := range container.Namespaces {
+		var shared []string
+		if namespace.SharedWithHost {
+			shared = append(shared, "[red]host[-]")
+		}
+		for _, id := range namespace.SharedWith {
+			shared = append(shared, "[yellow]"+shortContainerID(id)+"[-]")
+		}
+		if len(shared) == 0 {
+			shared = append(shared, "-")
+		}
+		details += fmt.Sprintf("%-8s %-12d %s\n", namespace.Type, namespace.Inode, strings.Join(shared, ", "))
+	}
+
+	details += fmt.Sprintf("[::b]UID Map:[::-] %s\n", formatIDMappings(container.UIDMap))
+	details += fmt.Sprintf("[::b]GID Map:[::-] %s\n", formatIDMappings(container.GIDMap))
+
+	return details
+}
+
+// formatIDMappings formats user namespace mappings as container→host ranges.
+func formatIDMappings(mappings []IDMapping) string {
+	if len(mappings) == 0 {
+		return "-"
+	}
+	if isIdentity(mappings) {
+		return "[red]identity (not remapped from the host)[-]"
+	}
+
+	var ranges []string
+	for _, mapping := range mappings {
+		ranges = append(ranges, fmt.Sprintf("%d-%d → %d-%d",
+			mapping.ContainerID, mapping.ContainerID+mapping.Size-1, mapping.HostID, mapping.HostID+mapping.Size-1))
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// shortContainerID abbreviates a container ID the way container runtimes display it.
+func shortContainerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// showSecurity displays the container's security profiles, capabilities, seccomp mode and
+// credentials, highlighting dangerous capabilities.
+func showSecurity(container Container) string {
+	security := container.Security
+
+	details := "\n[::b]=== Security ===[::-]\n"
 	for _, profile := range container.SecurityProfiles {
 		details += fmt.Sprintf("[::b]Profile:[::-] %s\n", profile)
 	}
 
+	if security.RunsAsRoot() {
+		if security.RootOnHost() {
+			details += "[::b]Running As Root:[::-] [red]yes (root on the host)[-]\n"
+		} else {
+			details += "[::b]Running As Root:[::-] [yellow]yes (remapped by the user namespace)[-]\n"
+		}
+	} else {
+		details += fmt.Sprintf("[::b]Running As Root:[::-] no (uid %d, gid %d)\n", security.UID, security.GID)
+	}
+	if security.UID != security.HostUID || security.GID != security.HostGID {
+		details += fmt.Sprintf("[::b]Host IDs:[::-] uid %d, gid %d\n", security.HostUID, security.HostGID)
+	}
+
+	if security.Seccomp == "filter" {
+		details += fmt.Sprintf("[::b]Seccomp:[::-] filter (%d filters)\n", security.SeccompFilters)
+	} else if security.Seccomp == "disabled" {
+		details += "[::b]Seccomp:[::-] [red]disabled[-]\n"
+	} else {
+		details += fmt.Sprintf("[::b]Seccomp:[::-] %s\n", security.Seccomp)
+	}
+
+	if security.NoNewPrivs {
+		details += "[::b]NoNewPrivs:[::-] yes\n"
+	} else {
+		details += "[::b]NoNewPrivs:[::-] [yellow]no[-]\n"
+	}
+
+	details += fmt.Sprintf("[::b]Effective Capabilities:[::-] %s\n", formatCapabilities(security.Effective))
+	details += fmt.Sprintf("[::b]Permitted Capabilities:[::-] %s\n", formatCapabilities(security.Permitted))
+	details += fmt.Sprintf("[::b]Bounding Capabilities:[::-] %s\n", formatCapabilities(security.Bounding))
+	details += fmt.Sprintf("[::b]Ambient Capabilities:[::-] %s\n", formatCapabilities(security.Ambient))
+
 	return details
 }
+
+// formatCapabilities lists capabilities, with the dangerous ones in red.
+func formatCapabilities(capabilities []string) string {
+	if len(capabilities) == 0 {
+		return "none"
+	}
+
+	formatted := make([]string, len(capabilities))
+	for i, capability := range capabilities {
+		if dangerousCapabilities[capability] {
+			formatted[i] = "[red]" + capability + "[-]"
+		} else {
+			formatted[i] = capability
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// showSpec displays the configuration the container was created with, from its bundle's
+// config.json. Environment variables are redacted like the observed ones.
+func showSpec(container Container) string {
+	details := "\n[::b]=== OCI Spec ===[::-]\n"
+
+	spec := container.Spec
+	if spec == nil {
+		if container.SpecError != "" {
+			return details + fmt.Sprintf("[red]Failed to read the bundle config.json:[-] %s\n", tview.Escape(container.SpecError))
+		}
+		return details + "No bundle config.json available\n"
+	}
+
+	details += fmt.Sprintf("[::b]Version:[::-] %s\n", spec.OciVersion)
+	if spec.Hostname != "" {
+		details += fmt.Sprintf("[::b]Hostname:[::-] %s\n", spec.Hostname)
+	}
+	if spec.Root != nil {
+		details += fmt.Sprintf("[::b]Root:[::-] %s (readonly: %t)\n", tview.Escape(spec.Root.Path), spec.Root.Readonly)
+	}
+
+	if process := spec.Process; process != nil {
+		details += fmt.Sprintf("[::b]Args:[::-] %s\n", tview.Escape(strings.Join(process.Args, " ")))
+		details += fmt.Sprintf("[::b]Cwd:[::-] %s\n", tview.Escape(process.Cwd))
+
+		user := fmt.Sprintf("%d:%d", process.User.UID, process.User.GID)
+		if process.User.Username != "" {
+			user = process.User.Username + " (" + user + ")"
+		}
+		if len(process.User.AdditionalGids) > 0 {
+			gids := make([]string, len(process.User.AdditionalGids))
+			for i, gid := range process.User.AdditionalGids {
+				gids[i] = strconv.FormatUint(uint64(gid), 10)
+			}
+			user += ", groups " + strings.Join(gids, ",")
+		}
+		details += fmt.Sprintf("[::b]User:[::-] %s\n", user)
+		details += fmt.Sprintf("[::b]Terminal:[::-] %t\n[::b]NoNewPrivileges:[::-] %t\n", process.Terminal, process.NoNewPrivileges)
+		if process.ApparmorProfile != "" {
+			details += fmt.Sprintf("[::b]AppArmor Profile:[::-] %s\n", process.ApparmorProfile)
+		}
+		if process.SelinuxLabel != "" {
+			details += fmt.Sprintf("[::b]SELinux Label:[::-] %s\n", process.SelinuxLabel)
+		}
+		if process.Capabilities != nil {
+			details += fmt.Sprintf("[::b]Bounding Capabilities:[::-] %s\n", formatCapabilities(process.Capabilities.Bounding))
+		}
+
+		if len(process.Rlimits) > 0 {
+			details += fmt.Sprintf("[::b]%-20s %20s %20s[::-]\n", "Rlimit", "Soft", "Hard")
+			for _, rlimit := range process.Rlimits {
+				details += fmt.Sprintf("%-20s %20d %20d\n", rlimit.Type, rlimit.Soft, rlimit.Hard)
+			}
+		}
+
+		details += "[::b]Env:[::-]\n"
+		for _, envVar := range process.Env {
+			key, value, _ := strings.Cut(envVar, "=")
+			if isSecret(key, value) && !isRevealed(container, key) {
+				value = "[red]********[-]"
+			} else {
+				value = tview.Escape(value)
+			}
+			details += fmt.Sprintf("  %s=%s\n", tview.Escape(key), value)
+		}
+	}
+
+	if len(spec.Mounts) > 0 {
+		details += fmt.Sprintf("[::b]%-40s %-10s %-40s %s[::-]\n", "Mount", "Type", "Source", "Options")
+		for _, mount := range spec.Mounts {
+			details += fmt.Sprintf("%-40s %-10s %-40s %s\n",
+				tview.Escape(mount.Destination), mount.Type, tview.Escape(mount.Source), strings.Join(mount.Options, ","))
+		}
+	}
+
+	if spec.Hooks != nil {
+		for _, stage := range spec.Hooks.stages() {
+			for _, hook := range stage.hooks {
+				details += fmt.Sprintf("[::b]Hook (%s):[::-] %s", stage.name, tview.Escape(hook.Path))
+				if len(hook.Args) > 1 {
+					details += " " + tview.Escape(strings.Join(hook.Args[1:], " "))
+				}
+				if hook.Timeout != nil {
+					details += fmt.Sprintf(" (timeout %ds)", *hook.Timeout)
+				}
+				details += "\n"
+			}
+		}
+	}
+
+	for _, namespace := range spec.Linux.Namespaces {
+		if namespace.Path != "" {
+			details += fmt.Sprintf("[::b]Namespace:[::-] %s joins %s\n", namespace.Type, tview.Escape(namespace.Path))
+		} else {
+			details += fmt.Sprintf("[::b]Namespace:[::-] %s\n", namespace.Type)
+		}
+	}
+	if spec.Linux.CgroupsPath != "" {
+		details += fmt.Sprintf("[::b]Cgroups Path:[::-] %s\n", tview.Escape(spec.Linux.CgroupsPath))
+	}
+	if len(spec.Linux.MaskedPaths) > 0 {
+		details += fmt.Sprintf("[::b]Masked Paths:[::-] %s\n", strings.Join(spec.Linux.MaskedPaths, ", "))
+	}
+	if len(spec.Linux.ReadonlyPaths) > 0 {
+		details += fmt.Sprintf("[::b]Readonly Paths:[::-] %s\n", strings.Join(spec.Linux.ReadonlyPaths, ", "))
+	}
+
+	if resources := spec.Linux.Resources; resources != nil {
+		if cpu := resources.CPU; cpu != nil {
+			details += fmt.Sprintf("[::b]CPU:[::-] shares %d, quota %d, period %d", cpu.Shares, cpu.Quota, cpu.Period)
+			if cpu.Cpus != "" {
+				details += fmt.Sprintf(", cpus %s", cpu.Cpus)
+			}
+			if cpu.Mems != "" {
+				details += fmt.Sprintf(", mems %s", cpu.Mems)
+			}
+			details += "\n"
+		}
+		if memory := resources.Memory; memory != nil {
+			details += fmt.Sprintf("[::b]Memory:[::-] limit %d, reservation %d, swap %d bytes\n", memory.Limit, memory.Reservation, memory.Swap)
+		}
+		if pids := resources.Pids; pids != nil {
+			details += fmt.Sprintf("[::b]Pids Limit:[::-] %d\n", pids.Limit)
+		}
+		if blockIO := resources.BlockIO; blockIO != nil {
+			details += fmt.Sprintf("[::b]Block IO:[::-] weight %d", blockIO.Weight)
+			for _, device := range blockIO.ThrottleReadBpsDevice {
+				details += fmt.Sprintf(", read %d:%d %s/s", device.Major, device.Minor, formatBytes(float64(device.Rate)))
+			}
+			for _, device := range blockIO.ThrottleWriteBpsDevice {
+				details += fmt.Sprintf(", write %d:%d %s/s", device.Major, device.Minor, formatBytes(float64(device.Rate)))
+			}
+			details += "\n"
+		}
+	}
+
+	return details
+}
+
+// processSortMode selects how sibling processes are ordered in the process tree.
+type processSortMode int
+
+const (
+	processSortCPU processSortMode = iota
+	processSortMemory
+)
+
+// processSort is the order currently applied to the process tree
+var processSort = processSortCPU
+
+// collapsedProcesses remembers which processes were collapsed in the tree across refreshes
+var collapsedProcesses = make(map[int]bool)
+
+func (m processSortMode) String() string {
+	if m == processSortMemory {
+		return "memory"
+	}
+	return "CPU"
+}
+
+// next returns the sort mode that follows m when toggling.
+func (m processSortMode) next() processSortMode {
+	return (m + 1) % 2
+}
+
+// showProcesses displays the container's processes as a tree of parents and children,
+// with siblings ordered by CPU or memory usage.
+func showProcesses(container Container, tree *tview.TreeView) {
+	// Keep the selection on the same process when the tree is rebuilt
+	selected := -1
+	if node := tree.GetCurrentNode(); node != nil {
+		if pid, ok := node.GetReference().(int); ok {
+			selected = pid
+		}
+	}
+
+	processes := append([]ProcessInfo(nil), container.TopProcesses...)
+	sort.Slice(processes, func(i, j int) bool {
+		if processSort == processSortMemory {
+			return processes[i].RSS > processes[j].RSS
+		}
+		return processes[i].CPU > processes[j].CPU
+	})
+
+	inContainer := make(map[int]bool)
+	for _, process := range processes {
+		inContainer[process.PID] = true
+	}
+
+	root := tview.NewTreeNode(fmt.Sprintf("%-8s %-8s %-10s %-3s %4s %7s %10s  %s", "PID", "NSPID", "USER", "S", "THR", "CPU%", "RSS", "COMMAND")).
+		SetSelectable(false).
+		SetColor(tcell.ColorYellow)
+
+	// Processes whose parent is outside the container are the roots of the tree
+	nodes := make(map[int]*tview.TreeNode)
+	for _, process := range processes {
+		text := fmt.Sprintf("%-8d %-8d %-10s %-3s %4d %7.2f %10s  %s",
+			process.PID, process.NSPID, process.User, process.State, process.Threads, process.CPU, formatBytes(float64(process.RSS)*1024), tview.Escape(process.CMD))
+		nodes[process.PID] = tview.NewTreeNode(text).
+			SetReference(process.PID).
+			SetExpanded(!collapsedProcesses[process.PID])
+	}
+
+	var current *tview.TreeNode
+	for _, process := range processes {
+		node := nodes[process.PID]
+		if inContainer[process.PPID] && process.PPID != process.PID {
+			nodes[process.PPID].AddChild(node)
+		} else {
+			root.AddChild(node)
+		}
+		if process.PID == selected {
+			current = node
+		}
+	}
+
+	drifted := make(map[string]bool)
+	for _, alert := range container.ProcessAlerts {
+		drifted[alert.Exe] = true
+	}
+
+	// Highlight drifted processes, and processes that have children since they can be
+	// expanded or collapsed
+	for _, process := range processes {
+		if drifted[process.Exe] {
+			nodes[process.PID].SetColor(tcell.ColorRed)
+		} else if len(nodes[process.PID].GetChildren()) > 0 {
+			nodes[process.PID].SetColor(tcell.ColorGreen)
+		} else {
+			nodes[process.PID].SetColor(tcell.ColorWhite)
+		}
+	}
+
+	if current == nil && len(root.GetChildren()) > 0 {
+		current = root.GetChildren()[0]
+	}
+
+	tree.SetRoot(root).SetCurrentNode(current)
+	tree.SetTitle(fmt.Sprintf(" Processes (%d, by %s) ", len(processes), processSort))
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Runtime is a container runtime backend that Tachyon discovers containers from.
+type Runtime interface {
+	// Name returns the name of the backend
+	Name() string
+	// List returns every container known to the runtime
+	List() ([]Container, error)
+	// Inspect returns a single container by its ID
+	Inspect(id string) (Container, error)
+	// Events streams container lifecycle events until the context is cancelled
+	Events(ctx context.Context) (<-chan Event, error)
+}
+
+// Event describes a change in a container's lifecycle.
+type Event struct {
+	Type        string // create, start, stop, pause or delete
+	ContainerID string
+	Time        time.Time
+}
+
+// containerRuntime is the backend selected for this run
+var containerRuntime Runtime
+
+// newRuntime creates the runtime backend selected in the config.
+func newRuntime(cfg Config) (Runtime, error) {
+	switch cfg.Runtime {
+	case "", "runc":
+		return &runcRuntime{roots: cfg.RuncRoots}, nil
+	case "docker":
+		return newDockerRuntime(cfg.DockerSocket), nil
+	case "cri":
+		runtime, err := newCRIRuntime(cfg.CRIEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		return runtime, nil
+	case "fake":
+		return newFakeRuntime(demoContainers()...), nil
+	default:
+		return nil, fmt.Errorf("unknown runtime %q", cfg.Runtime)
+	}
+}
+
+// statusEventType maps a container status to the event reported when a container enters it.
+func statusEventType(status string) string {
+	switch status {
+	case "running":
+		return "start"
+	case "paused":
+		return "pause"
+	case "created":
+		return "create"
+	default:
+		return "stop"
+	}
+}
+
+// pollEvents synthesizes lifecycle events for runtimes without an event stream by
+// listing the containers every interval and comparing their states.
+func pollEvents(ctx context.Context, list func() ([]Container, error), interval time.Duration) <-chan Event {
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		// Seed the known states so existing containers don't produce events
+		known := make(map[string]string)
+		if containers, err := list(); err == nil {
+			for _, container := range containers {
+				known[container.ID] = container.Status
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			containers, err := list()
+			if err != nil {
+				continue
+			}
+
+			now := time.Now()
+			var changes []Event
+			seen := make(map[string]string)
+			for _, container := range containers {
+				seen[container.ID] = container.Status
+				if status, ok := known[container.ID]; !ok || status != container.Status {
+					changes = append(changes, Event{Type: statusEventType(container.Status), ContainerID: container.ID, Time: now})
+				}
+			}
+			for id := range known {
+				if _, ok := seen[id]; !ok {
+					changes = append(changes, Event{Type: "delete", ContainerID: id, Time: now})
+				}
+			}
+			known = seen
+
+			for _, event := range changes {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+const (
+	// criEventInterval is how often the CRI endpoint is polled for lifecycle events
+	criEventInterval = 2 * time.Second
+	// criTimeout bounds each call to the CRI endpoint
+	criTimeout = 5 * time.Second
+)
+
+// criRuntime discovers containers through the CRI RuntimeService of a runtime endpoint
+// (containerd, CRI-O), speaking the same gRPC API as the kubelet and crictl.
+type criRuntime struct {
+	endpoint string
+	client   runtimeapi.RuntimeServiceClient
+}
+
+// criContainerInfo mirrors the parts of the verbose ContainerStatus info used by Tachyon.
+type criContainerInfo struct {
+	Pid         int `json:"pid"`
+	RuntimeSpec struct {
+		Annotations map[string]string `json:"annotations"`
+	} `json:"runtimeSpec"`
+}
+
+// newCRIRuntime creates a CRI backend talking to the given endpoint. Endpoints without
+// a scheme are taken to be unix sockets, like crictl does.
+func newCRIRuntime(endpoint string) (*criRuntime, error) {
+	target := endpoint
+	if !strings.Contains(target, "://") {
+		target = "unix://" + target
+	}
+
+	// The connection is established lazily, on the first call
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to CRI endpoint %s: %w", endpoint, err)
+	}
+
+	return &criRuntime{
+		endpoint: endpoint,
+		client:   runtimeapi.NewRuntimeServiceClient(conn),
+	}, nil
+}
+
+func (c *criRuntime) Name() string {
+	return "cri"
+}
+
+// List returns every container known to the CRI runtime, including exited ones.
+func (c *criRuntime) List() ([]Container, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), criTimeout)
+	defer cancel()
+
+	list, err := c.client.ListContainers(ctx, &runtimeapi.ListContainersRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers from %s: %w", c.endpoint, err)
+	}
+
+	// The list does not include PIDs, so get the status of each container
+	var containers []Container
+	for _, summary := range list.GetContainers() {
+		container, err := c.Inspect(summary.GetId())
+		if err != nil {
+			continue
+		}
+		containers = append(containers, container)
+	}
+
+	return containers, nil
+}
+
+// Inspect returns a single container from the CRI ContainerStatus call.
+func (c *criRuntime) Inspect(id string) (Container, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), criTimeout)
+	defer cancel()
+
+	// The verbose info carries the PID and the runtime spec
+	response, err := c.client.ContainerStatus(ctx, &runtimeapi.ContainerStatusRequest{ContainerId: id, Verbose: true})
+	if err != nil {
+		return Container{}, fmt.Errorf("failed to get the status of container %s from %s: %w", id, c.endpoint, err)
+	}
+	status := response.GetStatus()
+	if status == nil {
+		return Container{}, fmt.Errorf("no status for container %s from %s", id, c.endpoint)
+	}
+
+	var info criContainerInfo
+	if content, ok := response.GetInfo()["info"]; ok {
+		if err := json.Unmarshal([]byte(content), &info); err != nil {
+			return Container{}, fmt.Errorf("failed to unmarshal the info of container %s: %w", id, err)
+		}
+	}
+
+	// The runtime spec annotations carry the io.kubernetes.cri.* keys that the runc
+	// backend reports, the CRI annotations and labels carry the kubelet's
+	annotations := make(map[string]string)
+	for _, source := range []map[string]string{status.GetLabels(), status.GetAnnotations(), info.RuntimeSpec.Annotations} {
+		for key, value := range source {
+			annotations[key] = value
+		}
+	}
+
+	container := Container{
+		ID:          status.GetId(),
+		PID:         info.Pid,
+		Status:      criStatus(status.GetState()),
+		Created:     time.Unix(0, status.GetCreatedAt()).UTC().Format(time.RFC3339Nano),
+		Annotations: annotations,
+		Namespace:   c.Name(),
+		Root:        c.endpoint,
+		Image:       status.GetImage().GetImage(),
+	}
+
+	// Exited containers can keep reporting the PID their process had
+	if container.Status == "stopped" {
+		container.PID = 0
+	}
+
+	return container, nil
+}
+
+// Events polls the CRI endpoint, since the CRI event stream is not available on every runtime.
+func (c *criRuntime) Events(ctx context.Context) (<-chan Event, error) {
+	return pollEvents(ctx, c.List, criEventInterval), nil
+}
+
+// criStatus maps a CRI container state to the status names used by runc.
+func criStatus(state runtimeapi.ContainerState) string {
+	switch state {
+	case runtimeapi.ContainerState_CONTAINER_RUNNING:
+		return "running"
+	case runtimeapi.ContainerState_CONTAINER_CREATED:
+		return "created"
+	case runtimeapi.ContainerState_CONTAINER_EXITED:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// dockerRuntime discovers containers through the Docker engine API on a unix socket.
+type dockerRuntime struct {
+	socket string
+	client *http.Client
+}
+
+// dockerContainer mirrors the parts of the engine's container inspect response used by Tachyon.
+type dockerContainer struct {
+	ID      string `json:"Id"`
+	Created string `json:"Created"`
+	Name    string `json:"Name"`
+	State   struct {
+		Status string `json:"Status"`
+		Pid    int    `json:"Pid"`
+	} `json:"State"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	GraphDriver struct {
+		Data map[string]string `json:"Data"`
+	} `json:"GraphDriver"`
+}
+
+// dockerEvent mirrors a message from the engine's /events stream.
+type dockerEvent struct {
+	Type   string `json:"Type"`
+	Action string `json:"Action"`
+	Actor  struct {
+		ID string `json:"ID"`
+	} `json:"Actor"`
+	TimeNano int64 `json:"timeNano"`
+}
+
+// newDockerRuntime creates a Docker backend talking to the engine on the given socket.
+func newDockerRuntime(socket string) *dockerRuntime {
+	return &dockerRuntime{
+		socket: socket,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+func (d *dockerRuntime) Name() string {
+	return "docker"
+}
+
+// List returns every container known to the engine, including stopped ones.
+func (d *dockerRuntime) List() ([]Container, error) {
+	var summaries []struct {
+		ID string `json:"Id"`
+	}
+	if err := d.get(context.Background(), "/containers/json?all=1", &summaries); err != nil {
+		return nil, err
+	}
+
+	// The list endpoint does not report PIDs, so inspect each container
+	var containers []Container
+	for _, summary := range summaries {
+		container, err := d.Inspect(summary.ID)
+		if err != nil {
+			continue
+		}
+		containers = append(containers, container)
+	}
+
+	return containers, nil
+}
+
+// Inspect returns a single container from the engine's inspect endpoint.
+func (d *dockerRuntime) Inspect(id string) (Container, error) {
+	var inspect dockerContainer
+	if err := d.get(context.Background(), "/containers/"+url.PathEscape(id)+"/json", &inspect); err != nil {
+		return Container{}, err
+	}
+
+	status := inspect.State.Status
+	if status == "exited" || status == "dead" {
+		status = "stopped"
+	}
+
+	return Container{
+		ID:          inspect.ID,
+		PID:         inspect.State.Pid,
+		Status:      status,
+		Created:     inspect.Created,
+		RootFS:      inspect.GraphDriver.Data["MergedDir"],
+		Annotations: inspect.Config.Labels,
+		Namespace:   d.Name(),
+		Root:        d.socket,
+		Image:       inspect.Config.Image,
+	}, nil
+}
+
+// Events streams container events from the engine's /events endpoint.
+func (d *dockerRuntime) Events(ctx context.Context) (<-chan Event, error) {
+	filters := url.QueryEscape(`{"type":["container"]}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker/events?filters="+filters, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to docker socket %s: %w", d.socket, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("docker events returned %s", resp.Status)
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var message dockerEvent
+			if err := decoder.Decode(&message); err != nil {
+				return
+			}
+
+			// Exec and health check events carry a suffix, e.g. "exec_start: sh"
+			action, _, _ := strings.Cut(message.Action, ":")
+			event := Event{ContainerID: message.Actor.ID, Time: time.Unix(0, message.TimeNano)}
+			switch action {
+			case "create", "start", "pause", "stop":
+				event.Type = action
+			case "die", "kill":
+				event.Type = "stop"
+			case "unpause", "restart":
+				event.Type = "start"
+			case "destroy":
+				event.Type = "delete"
+			default:
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// get performs a GET request against the engine API and decodes the JSON response.
+func (d *dockerRuntime) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error connecting to docker socket %s: %w", d.socket, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("docker request %s returned %s", path, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode docker response for %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// fakeRuntime is an in-memory backend for exercising the TUI and the cache
+// without a real container runtime.
+type fakeRuntime struct {
+	mu          sync.Mutex
+	containers  map[string]Container
+	subscribers []chan Event
+}
+
+// newFakeRuntime creates a fake backend holding the given containers.
+func newFakeRuntime(containers ...Container) *fakeRuntime {
+	f := &fakeRuntime{containers: make(map[string]Container)}
+	for _, container := range containers {
+		f.containers[container.ID] = container
+	}
+	return f
+}
+
+// demoContainers returns a set of containers backed by Tachyon's own process,
+// so the details that are read from /proc can still be populated.
+func demoContainers() []Container {
+	created := time.Now().UTC().Format(time.RFC3339Nano)
+	var containers []Container
+	for i, name := range []string{"web", "worker", "db"} {
+		containers = append(containers, Container{
+			ID:      fmt.Sprintf("fake-%d", i+1),
+			PID:     os.Getpid(),
+			Status:  "running",
+			Created: created,
+			Annotations: map[string]string{
+				"io.kubernetes.cri.container-name": name,
+				"io.kubernetes.cri.sandbox-name":   "demo",
+				"io.kubernetes.pod.namespace":      "default",
+			},
+			Owner:     "root",
+			Namespace: "fake",
+			Image:     "example.com/" + name + ":latest",
+		})
+	}
+	return containers
+}
+
+func (f *fakeRuntime) Name() string {
+	return "fake"
+}
+
+// List returns a copy of every container held by the fake.
+func (f *fakeRuntime) List() ([]Container, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	containers := make([]Container, 0, len(f.containers))
+	for _, container := range f.containers {
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+// Inspect returns a single container held by the fake.
+func (f *fakeRuntime) Inspect(id string) (Container, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	container, ok := f.containers[id]
+	if !ok {
+		return Container{}, fmt.Errorf("container %s not found", id)
+	}
+	return container, nil
+}
+
+// Events subscribes to the events emitted by Add and Remove.
+func (f *fakeRuntime) Events(ctx context.Context) (<-chan Event, error) {
+	events := make(chan Event, 16)
+
+	f.mu.Lock()
+	f.subscribers = append(f.subscribers, events)
+	f.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for i, subscriber := range f.subscribers {
+			if subscriber == events {
+				f.subscribers = append(f.subscribers[:i], f.subscribers[i+1:]...)
+				break
+			}
+		}
+		close(events)
+	}()
+
+	return events, nil
+}
+
+// Add adds or replaces a container and emits an event for its status.
+func (f *fakeRuntime) Add(container Container) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.containers[container.ID] = container
+	f.emit(Event{Type: statusEventType(container.Status), ContainerID: container.ID, Time: time.Now()})
+}
+
+// Remove deletes a container and emits a delete event.
+func (f *fakeRuntime) Remove(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.containers, id)
+	f.emit(Event{Type: "delete", ContainerID: id, Time: time.Now()})
+}
+
+// emit delivers an event to every subscriber without blocking. The caller must hold f.mu.
+func (f *fakeRuntime) emit(event Event) {
+	for _, subscriber := range f.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
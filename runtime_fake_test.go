@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+// fakeContainer returns a running container backed by the test's own process, so that
+// it can be populated from /proc.
+func fakeContainer(id string) Container {
+	return Container{
+		ID:          id,
+		PID:         os.Getpid(),
+		Status:      "running",
+		Annotations: map[string]string{},
+		Namespace:   "fake",
+	}
+}
+
+// useFakeRuntime makes the cache discover containers from a fake backend for the
+// duration of a test.
+func useFakeRuntime(t *testing.T, containers ...Container) *fakeRuntime {
+	previous := containerRuntime
+	fake := newFakeRuntime(containers...)
+	containerRuntime = fake
+
+	t.Cleanup(func() {
+		containerRuntime = previous
+		cacheMutex.Lock()
+		defer cacheMutex.Unlock()
+		containerCache = make(map[string]Container)
+		lastRefreshed = time.Time{}
+	})
+
+	return fake
+}
+
+// refresh lists the containers again, bypassing the freshness check of the cache.
+func refresh(t *testing.T) []Container {
+	cacheMutex.Lock()
+	lastRefreshed = time.Time{}
+	cacheMutex.Unlock()
+
+	containers, err := GetContainers(true)
+	if err != nil {
+		t.Fatalf("GetContainers() error = %v", err)
+	}
+	return containers
+}
+
+// trackedIn reports in which of the per-container maps of the cache an ID is present.
+func trackedIn(id string) map[string]bool {
+	cacheMutex.RLock()
+	defer cacheMutex.RUnlock()
+
+	tracked := make(map[string]bool)
+	_, tracked["containerCache"] = containerCache[id]
+	_, tracked["cpuSamples"] = cpuSamples[id]
+	_, tracked["networkSamples"] = networkSamples[id]
+	_, tracked["ioSamples"] = ioSamples[id]
+	_, tracked["processSamples"] = processSamples[id]
+	_, tracked["executableBaselines"] = executableBaselines[id]
+	_, tracked["histories"] = histories[id]
+	return tracked
+}
+
+func TestFakeRuntimeCachePruning(t *testing.T) {
+	fake := useFakeRuntime(t, fakeContainer("fake-a"), fakeContainer("fake-b"))
+
+	containers := refresh(t)
+	if len(containers) != 2 {
+		t.Fatalf("GetContainers() returned %d containers, want 2", len(containers))
+	}
+	for _, container := range containers {
+		if container.PopulateError != "" {
+			t.Fatalf("container %s failed to populate: %s", container.ID, container.PopulateError)
+		}
+	}
+	for _, id := range []string{"fake-a", "fake-b"} {
+		for name, ok := range trackedIn(id) {
+			if !ok {
+				t.Errorf("%s is missing from %s after the first refresh", id, name)
+			}
+		}
+	}
+
+	fake.Remove("fake-b")
+	fake.Add(fakeContainer("fake-c"))
+	refresh(t)
+
+	for name, ok := range trackedIn("fake-b") {
+		if ok {
+			t.Errorf("removed container fake-b is still in %s", name)
+		}
+	}
+	for _, id := range []string{"fake-a", "fake-c"} {
+		for name, ok := range trackedIn(id) {
+			if !ok {
+				t.Errorf("%s is missing from %s after the second refresh", id, name)
+			}
+		}
+	}
+}
+
+func TestFakeRuntimeEvents(t *testing.T) {
+	fake := newFakeRuntime()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := fake.Events(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stopped := fakeContainer("fake-a")
+	stopped.Status = "stopped"
+	fake.Add(fakeContainer("fake-a"))
+	fake.Add(stopped)
+	fake.Remove("fake-a")
+
+	want := []string{"start", "stop", "delete"}
+	for _, eventType := range want {
+		event := <-events
+		if event.Type != eventType || event.ContainerID != "fake-a" {
+			t.Errorf("event = %+v, want %s of fake-a", event, eventType)
+		}
+	}
+
+	if _, err := fake.Inspect("fake-a"); err == nil {
+		t.Error("Inspect() found a removed container")
+	}
+
+	// Cancelling the subscription closes the channel
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("events channel still open after cancel")
+	}
+}
+
+func TestGetContainerByIDMarksCachedNamespaces(t *testing.T) {
+	fake := useFakeRuntime(t, fakeContainer("fake-a"), fakeContainer("fake-b"))
+	refresh(t)
+
+	// Every fake container is backed by the same process, so all of them share namespaces
+	fake.Add(fakeContainer("fake-c"))
+	if _, err := GetContainerByID("fake-c"); err != nil {
+		t.Fatalf("GetContainerByID() error = %v", err)
+	}
+
+	cacheMutex.RLock()
+	defer cacheMutex.RUnlock()
+	for _, id := range []string{"fake-a", "fake-b"} {
+		container := containerCache[id]
+		if len(container.Namespaces) == 0 {
+			t.Fatalf("%s has no namespaces", id)
+		}
+		for _, namespace := range container.Namespaces {
+			found := false
+			for _, other := range namespace.SharedWith {
+				found = found || other == "fake-c"
+			}
+			if !found {
+				t.Errorf("%s namespace %s is not marked as shared with fake-c: %v", id, namespace.Type, namespace.SharedWith)
+			}
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// runcState mirrors the parts of runc's <root>/<id>/state.json used by Tachyon.
+type runcState struct {
+	ID               string `json:"id"`
+	InitProcessPid   int    `json:"init_process_pid"`
+	InitProcessStart uint64 `json:"init_process_start"`
+	Created          string `json:"created"`
+	Config           struct {
+		Version string   `json:"version"`
+		Rootfs  string   `json:"rootfs"`
+		Labels  []string `json:"labels"`
+	} `json:"config"`
+	CgroupPaths    map[string]string `json:"cgroup_paths"`
+	NamespacePaths map[string]string `json:"namespace_paths"`
+}
+
+// runcEventInterval is how often the runc state roots are polled for lifecycle events
+const runcEventInterval = 2 * time.Second
+
+// runcRuntime discovers containers from a set of runc state roots.
+type runcRuntime struct {
+	roots []string
+}
+
+func (r *runcRuntime) Name() string {
+	return "runc"
+}
+
+// List returns the containers found in every configured root that exists on this host.
+func (r *runcRuntime) List() ([]Container, error) {
+	var containers []Container
+	var errs []error
+	for _, root := range r.roots {
+		// Skip roots that do not exist on this host
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+
+		rootContainers, err := listRuncRoot(root)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		containers = append(containers, rootContainers...)
+	}
+
+	// Only fail when no root could be listed at all
+	if len(containers) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return containers, nil
+}
+
+// Inspect looks up a container by ID in each configured root.
+func (r *runcRuntime) Inspect(id string) (Container, error) {
+	for _, root := range r.roots {
+		container, err := readRuncState(filepath.Join(root, id))
+		if err != nil {
+			continue
+		}
+		container.Root = root
+		container.Namespace = rootNamespace(root)
+		container.Image = container.Annotations["io.kubernetes.cri.image-name"]
+		return container, nil
+	}
+
+	// Fall back to a full listing in case the state files cannot be read
+	containers, err := r.List()
+	if err != nil {
+		return Container{}, err
+	}
+	for _, container := range containers {
+		if container.ID == id {
+			return container, nil
+		}
+	}
+
+	return Container{}, fmt.Errorf("container %s not found", id)
+}
+
+// Events polls the runc roots, since runc has no event stream of its own.
+func (r *runcRuntime) Events(ctx context.Context) (<-chan Event, error) {
+	return pollEvents(ctx, r.List, runcEventInterval), nil
+}
+
+// listRuncRoot lists the containers in a single runc state root and tags them
+// with the root and namespace they came from. The state files are read directly,
+// falling back to executing runc when they cannot be read.
+func listRuncRoot(root string) ([]Container, error) {
+	containers, err := readRuncRoot(root)
+	if err != nil {
+		containers, err = execRuncList(root)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	namespace := rootNamespace(root)
+	for i := range containers {
+		containers[i].Root = root
+		containers[i].Namespace = namespace
+		containers[i].Image = containers[i].Annotations["io.kubernetes.cri.image-name"]
+	}
+
+	return containers, nil
+}
+
+// execRuncList lists the containers in a runc state root by executing runc list.
+func execRuncList(root string) ([]Container, error) {
+	args := []string{"runc", "--root", root, "list", "--format", "json"}
+	// Only go through sudo when not already running as root
+	if os.Geteuid() != 0 {
+		args = append([]string{"sudo"}, args...)
+	}
+
+	// Execute runc to fetch information about running containers
+	out, err := exec.Command(args[0], args[1:]...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error executing runc command for root %s: %w", root, err)
+	}
+
+	if len(out) == 0 {
+		return nil, fmt.Errorf("runc output for root %s is empty", root)
+	}
+
+	var containers []Container
+	err = json.Unmarshal(out, &containers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the runc output for root %s: %w", root, err)
+	}
+
+	return containers, nil
+}
+
+// runcRootOwners are the directories runtimes keep one runc root per namespace in, with
+// the name of the runtime that owns them
+var runcRootOwners = map[string]string{
+	"/run/containerd/runc":     "containerd",
+	"/run/docker/runtime-runc": "docker",
+}
+
+// rootNamespace derives a namespace name from a runc state root, qualified with the
+// runtime owning it so that the namespaces of different runtimes cannot be confused,
+// e.g. /run/containerd/runc/k8s.io becomes containerd/k8s.io.
+func rootNamespace(root string) string {
+	root = filepath.Clean(root)
+	if owner, ok := runcRootOwners[filepath.Dir(root)]; ok {
+		return owner + "/" + filepath.Base(root)
+	}
+	return filepath.Base(root)
+}
+
+// readRuncRoot lists the containers in a runc state root by parsing each
+// container's state.json directly.
+func readRuncRoot(root string) ([]Container, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []Container
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		container, err := readRuncState(filepath.Join(root, entry.Name()))
+		if err != nil {
+			// Containers can disappear while the root is being read
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		containers = append(containers, container)
+	}
+
+	return containers, nil
+}
+
+// readRuncState builds a Container from the state.json in a container's state directory.
+func readRuncState(dir string) (Container, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "state.json"))
+	if err != nil {
+		return Container{}, err
+	}
+
+	var state runcState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return Container{}, fmt.Errorf("failed to unmarshal %s: %w", filepath.Join(dir, "state.json"), err)
+	}
+
+	container := Container{
+		OciVersion:     state.Config.Version,
+		ID:             state.ID,
+		PID:            state.InitProcessPid,
+		RootFS:         state.Config.Rootfs,
+		Created:        state.Created,
+		Annotations:    make(map[string]string),
+		Owner:          runcStateOwner(dir),
+		CgroupPaths:    state.CgroupPaths,
+		NamespacePaths: state.NamespacePaths,
+	}
+
+	// runc stores the bundle path and the OCI annotations as key=value labels
+	for _, label := range state.Config.Labels {
+		key, value, _ := strings.Cut(label, "=")
+		if key == "bundle" {
+			container.Bundle = value
+		} else {
+			container.Annotations[key] = value
+		}
+	}
+
+	container.Status = runcStatus(dir, state)
+
+	// Like runc list, report no PID for a stopped container: its init process is gone
+	// and the PID may belong to an unrelated process by now
+	if container.Status == "stopped" {
+		container.PID = 0
+	}
+
+	return container, nil
+}
+
+// runcStatus determines the container status the same way runc does: a container
+// whose init process is gone is stopped, a frozen cgroup means paused, and a
+// container still waiting on its exec fifo has been created but not started.
+func runcStatus(dir string, state runcState) string {
+	if state.InitProcessPid <= 0 {
+		return "stopped"
+	}
+
+	// The PID may have been reused, so compare the process start time as well
+	startTime, err := processStartTime(state.InitProcessPid)
+	if err != nil || startTime != state.InitProcessStart {
+		return "stopped"
+	}
+
+	if cgroupFrozen(state.CgroupPaths) {
+		return "paused"
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "exec.fifo")); err == nil {
+		return "created"
+	}
+
+	return "running"
+}
+
+// cgroupFrozen reports whether the container's cgroup is frozen on either
+// cgroup v2 (cgroup.freeze) or cgroup v1 (freezer.state).
+func cgroupFrozen(cgroupPaths map[string]string) bool {
+	if path, ok := cgroupPaths[""]; ok {
+		content, err := os.ReadFile(filepath.Join(path, "cgroup.freeze"))
+		if err == nil && strings.TrimSpace(string(content)) == "1" {
+			return true
+		}
+	}
+
+	if path, ok := cgroupPaths["freezer"]; ok {
+		content, err := os.ReadFile(filepath.Join(path, "freezer.state"))
+		if err == nil && strings.TrimSpace(string(content)) == "FROZEN" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runcStateOwner returns the name of the user owning a container's state directory,
+// which is how runc reports the container owner.
+func runcStateOwner(dir string) string {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return ""
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+
+	uid := strconv.FormatUint(uint64(stat.Uid), 10)
+	u, err := user.LookupId(uid)
+	if err != nil {
+		return "#" + uid
+	}
+
+	return u.Username
+}
+
+// processStartTime reads the start time of a process, in clock ticks since boot.
+func processStartTime(pid int) (uint64, error) {
+	stat, err := readProcStat(pid)
+	if err != nil {
+		return 0, err
+	}
+	return stat.StartTime, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultSecretPatterns match the names and values of environment variables holding
+// secrets: credential-like names, AWS access key IDs and JSON Web Tokens
+var defaultSecretPatterns = []string{
+	`(?i)(passw(or)?d|secret|token|credential|api_?key|access_?key|private_?key|_key$|^key$)`,
+	`\b(AKIA|ASIA)[0-9A-Z]{16}\b`,
+	`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`,
+}
+
+// secretPatterns are the compiled patterns environment variables are redacted by
+var secretPatterns []*regexp.Regexp
+
+// secretRevealDuration is how long a revealed secret stays visible
+const secretRevealDuration = 10 * time.Second
+
+// revealedSecret identifies the single environment variable currently revealed
+var revealedSecret struct {
+	containerID string
+	key         string
+	generation  int
+}
+
+// compileSecretPatterns compiles the configured secret patterns.
+func compileSecretPatterns(patterns []string) error {
+	secretPatterns = nil
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid secret pattern %q: %w", pattern, err)
+		}
+		secretPatterns = append(secretPatterns, re)
+	}
+	return nil
+}
+
+// isSecret reports whether an environment variable's name or value matches a secret pattern.
+func isSecret(key, value string) bool {
+	for _, re := range secretPatterns {
+		if re.MatchString(key) || re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretKeys lists the names of the container's environment variables that are masked.
+func secretKeys(container Container) []string {
+	var keys []string
+	for _, envVar := range container.EnvVariables {
+		if key, value, ok := strings.Cut(envVar, "="); ok && isSecret(key, value) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// revealNextSecret reveals the masked variable of the container that follows the one
+// currently revealed, and returns the generation of the reveal so it can be hidden again
+// once it expires. It returns false when the container has no masked variables.
+func revealNextSecret(container Container) (int, bool) {
+	keys := secretKeys(container)
+	if len(keys) == 0 {
+		return 0, false
+	}
+
+	next := 0
+	if revealedSecret.containerID == container.ID {
+		for i, key := range keys {
+			if key == revealedSecret.key {
+				next = (i + 1) % len(keys)
+				break
+			}
+		}
+	}
+
+	revealedSecret.containerID = container.ID
+	revealedSecret.key = keys[next]
+	revealedSecret.generation++
+	return revealedSecret.generation, true
+}
+
+// hideSecret masks the revealed variable again, unless another one was revealed since.
+func hideSecret(generation int) bool {
+	if revealedSecret.generation != generation {
+		return false
+	}
+	revealedSecret.containerID = ""
+	revealedSecret.key = ""
+	return true
+}
+
+// isRevealed reports whether the container's variable is the one currently revealed.
+func isRevealed(container Container, key string) bool {
+	return revealedSecret.containerID == container.ID && revealedSecret.key == key
+}
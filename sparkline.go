@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// sparklineWidth is the maximum number of samples drawn in a sparkline
+const sparklineWidth = 60
+
+// sparkBlocks are the characters used to draw sparklines, from lowest to highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as a row of unicode blocks scaled between zero and the largest value.
+func sparkline(values []float64) string {
+	if len(values) > sparklineWidth {
+		values = values[len(values)-sparklineWidth:]
+	}
+
+	var highest float64
+	for _, value := range values {
+		if value > highest {
+			highest = value
+		}
+	}
+
+	line := make([]rune, len(values))
+	for i, value := range values {
+		level := 0
+		if highest > 0 && value > 0 {
+			level = int(value / highest * float64(len(sparkBlocks)-1))
+		}
+		line[i] = sparkBlocks[level]
+	}
+
+	return string(line)
+}
+
+// historyValues extracts one metric from each sample.
+func historyValues(samples []MetricSample, metric func(MetricSample) float64) []float64 {
+	values := make([]float64, len(samples))
+	for i, sample := range samples {
+		values[i] = metric(sample)
+	}
+	return values
+}
+
+// historyRates computes the per-second rate of a cumulative metric between consecutive samples.
+func historyRates(samples []MetricSample, metric func(MetricSample) float64) []float64 {
+	var rates []float64
+	for i := 1; i < len(samples); i++ {
+		elapsed := samples[i].Time.Sub(samples[i-1].Time).Seconds()
+		delta := metric(samples[i]) - metric(samples[i-1])
+		// Counters reset when a container restarts
+		if elapsed <= 0 || delta < 0 {
+			rates = append(rates, 0)
+			continue
+		}
+		rates = append(rates, delta/elapsed)
+	}
+	return rates
+}
+
+// lastValue returns the most recent value, or zero when there are none.
+func lastValue(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return values[len(values)-1]
+}
+
+// historySpan describes how far back the drawn part of a history reaches, e.g. "last 10m0s".
+func historySpan(samples []MetricSample) string {
+	if len(samples) > sparklineWidth {
+		samples = samples[len(samples)-sparklineWidth:]
+	}
+	if len(samples) < 2 {
+		return "collecting"
+	}
+	span := samples[len(samples)-1].Time.Sub(samples[0].Time).Round(time.Second)
+	return fmt.Sprintf("last %s", span)
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. 1.5 MiB.
+func formatBytes(bytes float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	unit := 0
+	for bytes >= 1024 && unit < len(units)-1 {
+		bytes /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%.0f %s", bytes, units[unit])
+	}
+	return fmt.Sprintf("%.1f %s", bytes, units[unit])
+}